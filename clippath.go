@@ -0,0 +1,60 @@
+package svg
+
+import "fmt"
+
+// ClipPath implements SVG <clipPath> element
+//
+// The clipping region is the union of the geometry of its children, it is
+// applied to elements that refer to the clip path with the clip-path
+// property, see Svg.ClipPathOf.
+type ClipPath struct {
+	Node
+	Presentation
+	Transform *Transform
+	Units     GradientUnits // clipPathUnits, userSpaceOnUse when unspecified
+}
+
+func (c *ClipPath) read(src sourcer) (err error) {
+	err = c.item.read(src)
+	if err != nil {
+		return
+	}
+	err = c.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	if v, exists := src.Attr("transform"); exists {
+		c.Transform, err = parseTransformAttr(v)
+		if err != nil {
+			return fmt.Errorf("invalid transform: %w", err)
+		}
+	}
+	if v, exists := src.Attr("clipPathUnits"); exists {
+		if err = c.Units.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid clipPathUnits: %w", err)
+		}
+	}
+	return readChildren(src, &c.Items)
+}
+
+func (c *ClipPath) write(tgt targeter) {
+	c.item.write(tgt)
+	c.Presentation.write(tgt)
+	if c.Transform != nil {
+		tgt.Attr("transform", c.Transform.String())
+	}
+	tgt.Attr("clipPathUnits", c.Units.String())
+	c.writeItems(tgt)
+}
+
+// ClipPathOf returns the <clipPath> element referenced by the clip-path
+// property of it, or nil if the property is not specified or the reference
+// can not be resolved
+func (svg *Svg) ClipPathOf(it Item) *ClipPath {
+	p, ok := it.(presenter)
+	if !ok {
+		return nil
+	}
+	c, _ := svg.referenced(p.presentation().ClipPath).(*ClipPath)
+	return c
+}
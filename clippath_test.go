@@ -0,0 +1,70 @@
+package svg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClipPath(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24">
+		<style>.crop { clip-path: url(#circle) }</style>
+		<defs>
+			<clipPath id="circle" clipPathUnits="objectBoundingBox" transform="scale(0.5)">
+				<circle cx="1" cy="1" r="1" clip-rule="evenodd"/>
+			</clipPath>
+			<clipPath id="empty"/>
+		</defs>
+		<g id="g" clip-path="url(#circle)">
+			<rect id="r1" width="24" height="24" clip-path="none"/>
+		</g>
+		<rect id="r2" class="crop" width="24" height="24"/>
+		<rect id="r3" clip-path="url(#missing)"/>
+		<rect id="r4" clip-path="url(#g)"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	circle := doc.ByID("circle").(*ClipPath)
+	if circle.Units != GradientUnitsObjectBoundingBox || circle.Transform == nil || len(circle.Items) != 1 {
+		t.Errorf("clipPath = %+v", circle)
+	}
+	if r := circle.Items[0].(*Circle).ClipRule; r == nil || *r != FillRuleEvenOdd {
+		t.Errorf("clip-rule = %v", r)
+	}
+	if u := doc.ByID("empty").(*ClipPath).Units; u != GradientUnitsUnspecified {
+		t.Errorf("unspecified clipPathUnits = %v", u)
+	}
+
+	for id, want := range map[string]*ClipPath{
+		"g":  circle,
+		"r1": nil,
+		"r2": circle,
+		"r3": nil,
+		"r4": nil,
+	} {
+		if got := doc.ClipPathOf(doc.ByID(id)); got != want {
+			t.Errorf("ClipPathOf(%s) = %p, want %p", id, got, want)
+		}
+	}
+	if r := doc.ByID("r1").(*Rect).ClipPath; r == nil || r.String() != "none" {
+		t.Errorf("clip-path = %v", r)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+
+	for _, in := range []string{
+		`<svg><rect clip-path="circle"/></svg>`,
+		`<svg><rect clip-path="url(#a) url(#b)"/></svg>`,
+		`<svg><rect clip-rule="odd"/></svg>`,
+		`<svg><clipPath clipPathUnits="user"/></svg>`,
+	} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package svg
+
+import "reflect"
+
+var paintServerType = reflect.TypeOf((*PaintServer)(nil)).Elem()
+var gradientPtrType = reflect.TypeOf((*Gradient)(nil))
+
+// cloneItem returns a deep copy of the item tree rooted at it. Paint server
+// bindings are references to other elements of the document, these are
+// kept as is.
+func cloneItem(it Item) Item {
+	ret := deepClone(it)
+	Walk(ret, func(it Item) bool {
+		if svg, ok := it.(*Svg); ok {
+			// the registry refers to the original items
+			svg.ids = nil
+			svg.duplicates = nil
+		}
+		return true
+	})
+	return ret
+}
+
+// deepClone returns a deep copy of v
+func deepClone[T any](v T) T {
+	return deepCopy(reflect.ValueOf(&v).Elem(), map[uintptr]reflect.Value{}).Interface().(T)
+}
+
+func deepCopy(v reflect.Value, memo map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Type() == gradientPtrType {
+			return v
+		}
+		if c, ok := memo[v.Pointer()]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		memo[v.Pointer()] = c
+		c.Elem().Set(deepCopy(v.Elem(), memo))
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() || v.Type() == paintServerType {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), memo))
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), memo))
+		}
+		return c
+
+	case reflect.Struct:
+		// unexported fields are copied by value, the unexported slices and
+		// maps of the item are replaced with copies, the id registry is
+		// dropped by cloneItem
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		copyFields(c, v, memo)
+		if b, ok := c.Addr().Interface().(interface{ base() *item }); ok {
+			b.base().copyUnexported()
+		}
+		return c
+
+	default:
+		return v
+	}
+}
+
+// copyFields replaces the exported fields of c with deep copies of those of
+// v, including the fields that are promoted from unexported embedded structs
+func copyFields(c, v reflect.Value, memo map[uintptr]reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := c.Field(i)
+		if f.CanSet() {
+			f.Set(deepCopy(v.Field(i), memo))
+		} else if v.Type().Field(i).Anonymous && f.Kind() == reflect.Struct {
+			copyFields(f, v.Field(i), memo)
+		}
+	}
+}
+
+// copyUnexported replaces the unexported slices and maps with their copies
+func (it *item) copyUnexported() {
+	if it.extraStyle != nil {
+		it.extraStyle = append([]declaration{}, it.extraStyle...)
+	}
+	if it.sources != nil {
+		sources := make(map[string]propertySource, len(it.sources))
+		for k, v := range it.sources {
+			sources[k] = v
+		}
+		it.sources = sources
+	}
+}
@@ -0,0 +1,147 @@
+package svg
+
+import (
+	"strings"
+)
+
+// Conditions holds conditional processing attributes. Nil values stand for
+// attributes that are not specified, these are always satisfied.
+type Conditions struct {
+	RequiredFeatures   *string // space separated feature strings
+	RequiredExtensions *string // space separated extension URIs
+	SystemLanguage     *string // comma separated language tags
+}
+
+func (c *Conditions) read(src sourcer) {
+	for _, a := range []struct {
+		name string
+		v    **string
+	}{
+		{"requiredFeatures", &c.RequiredFeatures},
+		{"requiredExtensions", &c.RequiredExtensions},
+		{"systemLanguage", &c.SystemLanguage},
+	} {
+		if s, ok := src.Attr(a.name); ok {
+			*a.v = &s
+		}
+	}
+}
+
+func (c *Conditions) write(tgt targeter) {
+	// an empty value is meaningful, it never matches
+	if c.RequiredFeatures != nil {
+		tgt.ExtraAttr("requiredFeatures", *c.RequiredFeatures)
+	}
+	if c.RequiredExtensions != nil {
+		tgt.ExtraAttr("requiredExtensions", *c.RequiredExtensions)
+	}
+	if c.SystemLanguage != nil {
+		tgt.ExtraAttr("systemLanguage", *c.SystemLanguage)
+	}
+}
+
+// Environment describes the capabilities and the preferences of a user agent
+// that conditional processing attributes are tested against
+type Environment struct {
+	Features   []string // supported feature strings
+	Extensions []string // supported extension URIs
+	Languages  []string // preferred language tags, such as "en" or "fr-CA"
+}
+
+// Test reports whether the conditional processing attributes of it are
+// satisfied within env
+func (env *Environment) Test(it Item) bool {
+	b, ok := it.(interface{ base() *item })
+	if !ok {
+		return true
+	}
+	c := &b.base().Conditions
+	if c.RequiredFeatures != nil && !containsAll(env.Features, strings.Fields(*c.RequiredFeatures)) {
+		return false
+	}
+	if c.RequiredExtensions != nil && !containsAll(env.Extensions, strings.Fields(*c.RequiredExtensions)) {
+		return false
+	}
+	if c.SystemLanguage != nil && !env.matchLanguage(*c.SystemLanguage) {
+		return false
+	}
+	return true
+}
+
+// containsAll reports whether all of the required values are supported, an
+// empty list of required values is never satisfied
+func containsAll(supported, required []string) bool {
+	if len(required) == 0 {
+		return false
+	}
+	for _, r := range required {
+		found := false
+		for _, s := range supported {
+			found = found || s == r
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLanguage reports whether one of the preferred languages matches one
+// of the comma separated language tags, either exactly or as a prefix that is
+// followed by '-', such as "en" for "en-US"
+func (env *Environment) matchLanguage(tags string) bool {
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		for _, l := range env.Languages {
+			if strings.EqualFold(tag, l) ||
+				len(tag) > len(l) && tag[len(l)] == '-' && strings.EqualFold(tag[:len(l)], l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Anchor implements SVG <a> element, a container that links its content
+type Anchor struct {
+	Group
+	Href   string
+	Target string
+}
+
+func (a *Anchor) read(src sourcer) (err error) {
+	a.Href = a.readHref(src)
+	a.Target, _ = src.Attr("target")
+	return a.Group.read(src)
+}
+
+func (a *Anchor) write(tgt targeter) {
+	a.writeHref(tgt, a.Href)
+	tgt.Attr("target", a.Target)
+	a.Group.write(tgt)
+}
+
+// Switch implements SVG <switch> element, only the first of its children
+// that satisfies the conditional processing attributes is rendered
+type Switch struct {
+	Group
+}
+
+// Evaluate returns the child element that is rendered within env, or nil if
+// none of the children satisfies its conditions. Descriptive elements and
+// comments are not considered.
+func (s *Switch) Evaluate(env *Environment) Item {
+	for _, it := range s.Items {
+		switch it.(type) {
+		case *Title, *Desc, *Metadata, *Comment:
+			continue
+		}
+		if env.Test(it) {
+			return it
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package svg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnchorAndSwitch(t *testing.T) {
+	data := `<svg xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 24 24">
+		<a id="link" xlink:href="https://example.com/" target="_blank" fill="red">
+			<rect width="10" height="10"/>
+			<g><circle r="2"/></g>
+		</a>
+		<switch id="sw">
+			<title>localized</title>
+			<text id="fr" systemLanguage="fr, fr-CA">Bonjour</text>
+			<text id="en" systemLanguage="en-US,en-GB">Hello</text>
+			<g id="ext" requiredExtensions="http://example.com/ext"/>
+			<g id="never" requiredFeatures=""/>
+			<text id="fallback">Hi</text>
+		</switch>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := doc.ByID("link").(*Anchor)
+	if a.Href != "https://example.com/" || a.Target != "_blank" || len(a.Items) != 2 || a.Fill == nil {
+		t.Errorf("anchor = %+v", a)
+	}
+	if _, ok := a.Items[1].(*Group).Items[0].(*Circle); !ok {
+		t.Errorf("anchor content = %+v", a.Items)
+	}
+
+	sw := doc.ByID("sw").(*Switch)
+	for _, tc := range []struct {
+		env  Environment
+		want string
+	}{
+		{Environment{}, "fallback"},
+		{Environment{Languages: []string{"en"}}, "en"},
+		{Environment{Languages: []string{"EN-gb"}}, "en"},
+		{Environment{Languages: []string{"de", "fr-CA"}}, "fr"},
+		{Environment{Languages: []string{"e"}}, "fallback"},
+		{Environment{Extensions: []string{"http://example.com/ext"}}, "ext"},
+		{Environment{Features: []string{""}}, "fallback"},
+	} {
+		got := sw.Evaluate(&tc.env)
+		if got == nil || got.ID() != tc.want {
+			t.Errorf("Evaluate(%+v) = %v, want %s", tc.env, got, tc.want)
+		}
+	}
+	if got := (&Switch{}).Evaluate(&Environment{}); got != nil {
+		t.Errorf("empty switch = %v", got)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+	if !strings.Contains(out, `requiredFeatures=""`) {
+		t.Errorf("empty condition is not written:\n%s", out)
+	}
+}
@@ -0,0 +1,402 @@
+package svg
+
+import (
+	"sort"
+	"strings"
+)
+
+// declaration is a single CSS property declaration
+type declaration struct {
+	Property  string
+	Value     string
+	Important bool
+}
+
+// stripComments removes CSS /* comments */
+func stripComments(s string) string {
+	for {
+		i := strings.Index(s, "/*")
+		if i < 0 {
+			return s
+		}
+		j := strings.Index(s[i+2:], "*/")
+		if j < 0 {
+			return s[:i]
+		}
+		s = s[:i] + " " + s[i+2+j+2:]
+	}
+}
+
+// splitTopLevel splits s at sep bytes that are not enclosed in quotes or
+// parentheses
+func splitTopLevel(s string, sep byte) []string {
+	ret := []string{}
+	depth := 0
+	quote := byte(0)
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+		case c == sep && depth == 0:
+			ret = append(ret, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(ret, s[start:])
+}
+
+// parseDeclarations parses a CSS declaration list, such as the content of
+// the style attribute. Malformed declarations are skipped, as CSS requires.
+func parseDeclarations(s string) []declaration {
+	ret := []declaration{}
+	for _, part := range splitTopLevel(stripComments(s), ';') {
+		colon := strings.IndexByte(part, ':')
+		if colon < 0 {
+			continue
+		}
+		d := declaration{
+			Property: strings.ToLower(strings.TrimSpace(part[:colon])),
+			Value:    strings.TrimSpace(part[colon+1:]),
+		}
+		if i := strings.LastIndexByte(d.Value, '!'); i >= 0 &&
+			strings.EqualFold(strings.TrimSpace(d.Value[i+1:]), "important") {
+			d.Important = true
+			d.Value = strings.TrimSpace(d.Value[:i])
+		}
+		if d.Property == "" || d.Value == "" {
+			continue
+		}
+		ret = append(ret, d)
+	}
+	return ret
+}
+
+// formatDeclarations formats a CSS declaration list
+func formatDeclarations(dd []declaration) string {
+	ss := make([]string, 0, len(dd))
+	for _, d := range dd {
+		s := d.Property + ":" + d.Value
+		if d.Important {
+			s += " !important"
+		}
+		ss = append(ss, s)
+	}
+	return strings.Join(ss, ";")
+}
+
+// cssElement describes an element for the purpose of selector matching
+type cssElement struct {
+	tag     string
+	id      string
+	classes []string
+	parent  *cssElement
+}
+
+func (e *cssElement) hasClass(c string) bool {
+	for _, ec := range e.classes {
+		if ec == c {
+			return true
+		}
+	}
+	return false
+}
+
+// compoundSelector is a sequence of simple selectors, such as rect.st0#a
+type compoundSelector struct {
+	tag     string // empty for the universal selector
+	id      string
+	classes []string
+}
+
+func (cs *compoundSelector) matches(e *cssElement) bool {
+	if cs.tag != "" && cs.tag != e.tag {
+		return false
+	}
+	if cs.id != "" && cs.id != e.id {
+		return false
+	}
+	for _, c := range cs.classes {
+		if !e.hasClass(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// selector is a complex selector, compounds are joined with descendant or
+// child combinators
+type selector struct {
+	compounds []compoundSelector
+	child     []bool // child[i] is true when compounds[i] and compounds[i+1] are joined with '>'
+}
+
+func (s *selector) specificity() int {
+	a, b, c := 0, 0, 0
+	for _, cs := range s.compounds {
+		if cs.id != "" {
+			a++
+		}
+		b += len(cs.classes)
+		if cs.tag != "" {
+			c++
+		}
+	}
+	return a<<16 | b<<8 | c
+}
+
+func (s *selector) matches(e *cssElement) bool {
+	return s.matchAt(len(s.compounds)-1, e)
+}
+
+func (s *selector) matchAt(i int, e *cssElement) bool {
+	if !s.compounds[i].matches(e) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if s.child[i-1] {
+		return e.parent != nil && s.matchAt(i-1, e.parent)
+	}
+	for p := e.parent; p != nil; p = p.parent {
+		if s.matchAt(i-1, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// parseSelector parses a complex selector, ok is false for selectors that
+// are not supported
+func parseSelector(s string) (sel selector, ok bool) {
+	s = strings.TrimSpace(s)
+	child := false
+	for len(s) > 0 {
+		cs := compoundSelector{}
+		n := 0
+		if s[0] == '*' {
+			s = s[1:]
+			n++
+		}
+		for len(s) > 0 {
+			c := s[0]
+			if c != '.' && c != '#' && !isIdentChar(c) {
+				break
+			}
+			if c == '.' || c == '#' {
+				s = s[1:]
+			}
+			i := 0
+			for i < len(s) && isIdentChar(s[i]) {
+				i++
+			}
+			if i == 0 {
+				return sel, false
+			}
+			switch c {
+			case '.':
+				cs.classes = append(cs.classes, s[:i])
+			case '#':
+				if cs.id != "" && cs.id != s[:i] {
+					return sel, false
+				}
+				cs.id = s[:i]
+			default:
+				if n > 0 {
+					return sel, false
+				}
+				cs.tag = s[:i]
+			}
+			s = s[i:]
+			n++
+		}
+		if n == 0 {
+			return sel, false
+		}
+		if len(sel.compounds) > 0 {
+			sel.child = append(sel.child, child)
+		}
+		sel.compounds = append(sel.compounds, cs)
+
+		// combinator
+		t := strings.TrimLeft(s, " \t\r\n\f")
+		child = false
+		if len(t) > 0 && t[0] == '>' {
+			child = true
+			t = strings.TrimLeft(t[1:], " \t\r\n\f")
+		} else if len(t) == len(s) && len(t) > 0 {
+			// neither whitespace nor a supported combinator
+			return sel, false
+		}
+		if len(t) == 0 && child {
+			return sel, false
+		}
+		s = t
+	}
+	return sel, len(sel.compounds) > 0
+}
+
+type cssRule struct {
+	selectors    []selector
+	declarations []declaration
+}
+
+// styleSheet is a parsed CSS style sheet
+type styleSheet struct {
+	rules []cssRule
+}
+
+// parseStyleSheet parses CSS rules, rules with unsupported selectors and
+// at-rules are skipped
+func parseStyleSheet(s string) *styleSheet {
+	ss := &styleSheet{}
+	s = stripComments(s)
+
+	// skipBlock skips content up to and including the matching '}'
+	skipBlock := func() {
+		depth := 1
+		for i := 0; i < len(s); i++ {
+			switch s[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					s = s[i+1:]
+					return
+				}
+			}
+		}
+		s = ""
+	}
+
+	for {
+		s = strings.TrimSpace(s)
+		s = strings.TrimPrefix(s, "<!--")
+		s = strings.TrimPrefix(s, "-->")
+		s = strings.TrimSpace(s)
+		if len(s) == 0 {
+			break
+		}
+		ob := strings.IndexByte(s, '{')
+		if s[0] == '@' {
+			// at-rule, either terminated with ';' or followed by a block
+			sc := strings.IndexByte(s, ';')
+			if sc >= 0 && (ob < 0 || sc < ob) {
+				s = s[sc+1:]
+				continue
+			}
+		}
+		if ob < 0 {
+			break
+		}
+		atRule := s[0] == '@'
+		prelude := s[:ob]
+		s = s[ob+1:]
+		if atRule {
+			skipBlock()
+			continue
+		}
+		cb := strings.IndexByte(s, '}')
+		if cb < 0 {
+			cb = len(s)
+		}
+		body := s[:cb]
+		if cb < len(s) {
+			cb++
+		}
+		s = s[cb:]
+
+		r := cssRule{declarations: parseDeclarations(body)}
+		valid := true
+		for _, part := range splitTopLevel(prelude, ',') {
+			sel, ok := parseSelector(part)
+			if !ok {
+				valid = false
+				break
+			}
+			r.selectors = append(r.selectors, sel)
+		}
+		if valid && len(r.selectors) > 0 && len(r.declarations) > 0 {
+			ss.rules = append(ss.rules, r)
+		}
+	}
+	return ss
+}
+
+// match returns declarations from all rules that match e, ordered by
+// ascending specificity and source order
+func (ss *styleSheet) match(e *cssElement) []declaration {
+	if ss == nil || e == nil {
+		return nil
+	}
+	type matched struct {
+		specificity int
+		dd          []declaration
+	}
+	mm := []matched{}
+	for _, r := range ss.rules {
+		best := -1
+		for j := range r.selectors {
+			if r.selectors[j].matches(e) {
+				if sp := r.selectors[j].specificity(); sp > best {
+					best = sp
+				}
+			}
+		}
+		if best >= 0 {
+			mm = append(mm, matched{best, r.declarations})
+		}
+	}
+	sort.SliceStable(mm, func(i, j int) bool {
+		return mm[i].specificity < mm[j].specificity
+	})
+	ret := []declaration{}
+	for _, m := range mm {
+		ret = append(ret, m.dd...)
+	}
+	return ret
+}
+
+// cascade orders style sheet and inline declarations, so that applying them
+// in sequence produces the cascaded values: normal declarations from the
+// style sheet, normal inline declarations, then important declarations in
+// the same order
+func cascade(sheet, inline []declaration) []cascaded {
+	ret := []cascaded{}
+	for _, important := range []bool{false, true} {
+		for i, dd := range [][]declaration{sheet, inline} {
+			for _, d := range dd {
+				if d.Important == important {
+					ret = append(ret, cascaded{declaration: d, inline: i == 1})
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// cascaded is a declaration that is tagged with its origin
+type cascaded struct {
+	declaration
+	inline bool // from the style attribute, otherwise from the style sheet
+}
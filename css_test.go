@@ -0,0 +1,129 @@
+package svg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSelectorSpecificity(t *testing.T) {
+	tests := []struct {
+		sel  string
+		want int
+	}{
+		{"*", 0},
+		{"rect", 1},
+		{".st0", 1 << 8},
+		{"g .a.b", 2<<8 | 1},
+		{"#x", 1 << 16},
+		{"svg > g#x rect.st0", 1<<16 | 1<<8 | 3},
+	}
+	for _, tt := range tests {
+		sel, ok := parseSelector(tt.sel)
+		if !ok {
+			t.Errorf("parseSelector(%q) failed", tt.sel)
+			continue
+		}
+		if got := sel.specificity(); got != tt.want {
+			t.Errorf("specificity(%q) = %x, want %x", tt.sel, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"a:hover", "[fill]", "a + b", "a >", "p::before", ""} {
+		if _, ok := parseSelector(s); ok {
+			t.Errorf("parseSelector(%q): expected failure", s)
+		}
+	}
+}
+
+func TestSelectorMatching(t *testing.T) {
+	root := &cssElement{tag: "svg"}
+	g := &cssElement{tag: "g", id: "layer", classes: []string{"a"}, parent: root}
+	r := &cssElement{tag: "rect", classes: []string{"st0", "st1"}, parent: g}
+
+	tests := []struct {
+		sel  string
+		want bool
+	}{
+		{"rect", true},
+		{"*", true},
+		{".st0.st1", true},
+		{"rect.st2", false},
+		{"svg rect", true},
+		{"svg > rect", false},
+		{"#layer > .st1", true},
+		{"g.a rect", true},
+		{"g.b rect", false},
+		{"circle", false},
+	}
+	for _, tt := range tests {
+		sel, ok := parseSelector(tt.sel)
+		if !ok {
+			t.Errorf("parseSelector(%q) failed", tt.sel)
+			continue
+		}
+		if got := sel.matches(r); got != tt.want {
+			t.Errorf("%q matches = %v, want %v", tt.sel, got, tt.want)
+		}
+	}
+}
+
+func TestStyleSheetCascade(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24">
+		<style type="text/css"><![CDATA[
+			@import url(other.css);
+			@media print { rect { fill: #000 } }
+			.st0{fill:#E30613;}
+			.st1, .st2 {fill:#00ff00; stroke:#0000ff}
+			rect.st1 { fill: #ffff00 }
+			g rect { stroke-width: 3 }
+			#r3 { opacity: 0.5 !important }
+			a:hover { fill: #fff }
+		]]></style>
+		<path class="st0" fill="#000" d="M0,0"/>
+		<g class="st2">
+			<rect class="st1" fill="#000"/>
+			<rect id="r3" class="st1" style="fill:#00ffff; opacity:1"/>
+		</g>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Items[0].(*Style); !ok {
+		t.Fatalf("style element is not preserved")
+	}
+	p := doc.Items[1].(*Path)
+	if p.Fill == nil || p.Fill.Color != (RGB{0xe3, 0x06, 0x13}) {
+		t.Errorf("class rule does not override the fill attribute: %v", p.Fill)
+	}
+	g := doc.Items[2].(*Group)
+	if g.Fill == nil || g.Fill.Color != (RGB{0, 0xff, 0}) {
+		t.Errorf("grouped selector does not apply to <g>: %v", g.Fill)
+	}
+	r1 := g.Items[0].(*Rect)
+	if r1.Fill == nil || r1.Fill.Color != (RGB{0xff, 0xff, 0}) {
+		t.Errorf("more specific rule should win: %v", r1.Fill)
+	}
+	if r1.Stroke == nil || r1.Stroke.Color != (RGB{0, 0, 0xff}) || r1.StrokeWidth != "3" {
+		t.Errorf("stroke = %v, stroke-width = %q", r1.Stroke, r1.StrokeWidth)
+	}
+	r3 := g.Items[1].(*Rect)
+	if r3.Fill == nil || r3.Fill.Color != (RGB{0, 0xff, 0xff}) {
+		t.Errorf("style attribute should win over rules: %v", r3.Fill)
+	}
+	if r3.Opacity == nil || *r3.Opacity != 0.5 {
+		t.Errorf("important rule should win over style attribute: %v", r3.Opacity)
+	}
+	if r3.Class() != "st1" {
+		t.Errorf("class = %q", r3.Class())
+	}
+
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	for _, want := range []string{`<style type="text/css">`, `.st0{fill:#E30613;}`, `class="st1"`} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("written document does not contain %s:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package svg
+
+import "strings"
+
+// Title implements SVG <title> element, which provides the accessible name
+// of its parent element
+type Title struct {
+	item
+	Content string
+}
+
+func (t *Title) read(src sourcer) (err error) {
+	err = t.item.read(src)
+	if err != nil {
+		return
+	}
+	return src.ForEachChild(nil, func(text string) error {
+		t.Content += text
+		return nil
+	})
+}
+
+func (t *Title) write(tgt targeter) {
+	t.item.write(tgt)
+	tgt.Text(t.Content)
+}
+
+// Desc implements SVG <desc> element, which provides the accessible
+// description of its parent element
+type Desc struct {
+	item
+	Content string
+}
+
+func (d *Desc) read(src sourcer) (err error) {
+	err = d.item.read(src)
+	if err != nil {
+		return
+	}
+	return src.ForEachChild(nil, func(text string) error {
+		d.Content += text
+		return nil
+	})
+}
+
+func (d *Desc) write(tgt targeter) {
+	d.item.write(tgt)
+	tgt.Text(d.Content)
+}
+
+// Metadata implements SVG <metadata> element
+//
+// The content is kept verbatim as XML markup, such as RDF descriptions
+// written by editors, and is written back as is.
+type Metadata struct {
+	item
+	Content string
+}
+
+func (m *Metadata) read(src sourcer) (err error) {
+	err = m.item.read(src)
+	if err != nil {
+		return
+	}
+	m.Content, err = src.RawContent()
+	return
+}
+
+func (m *Metadata) write(tgt targeter) {
+	m.item.write(tgt)
+	tgt.Raw(m.Content)
+}
+
+// normalizeSpace collapses whitespace sequences into single spaces and
+// trims the leading and trailing whitespace
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// AccessibleName returns the accessible name of an element, which is the
+// value of its aria-label attribute, or the content of its first <title>
+// child, with normalized whitespace
+func AccessibleName(it Item) string {
+	if b, ok := it.(interface{ base() *item }); ok {
+		if v, ok := b.base().ExtraAttr("aria-label"); ok && normalizeSpace(v) != "" {
+			return normalizeSpace(v)
+		}
+	}
+	if c, ok := it.(container); ok {
+		for _, ch := range c.children() {
+			if t, ok := ch.(*Title); ok {
+				return normalizeSpace(t.Content)
+			}
+		}
+	}
+	return ""
+}
+
+// AccessibleDescription returns the accessible description of an element,
+// which is the content of its first <desc> child with normalized whitespace
+func AccessibleDescription(it Item) string {
+	if c, ok := it.(container); ok {
+		for _, ch := range c.children() {
+			if d, ok := ch.(*Desc); ok {
+				return normalizeSpace(d.Content)
+			}
+		}
+	}
+	return ""
+}
+
+// SetAccessibleName replaces the content of the first <title> child of an
+// element, or inserts a new <title> as its first child. An empty name
+// removes the <title>. Elements that can not have a <title> are not changed.
+func SetAccessibleName(it Item, name string) {
+	switch it := it.(type) {
+	case *Text:
+		setTitle(&it.Content, name)
+	case *TSpan:
+		setTitle(&it.Content, name)
+	case *TextPath:
+		setTitle(&it.Content, name)
+	case interface{ node() *Node }:
+		setTitle(&it.node().Items, name)
+	case interface{ shape() *shapeNode }:
+		setTitle(&it.shape().Items, name)
+	}
+}
+
+// SetAccessibleDescription replaces the content of the first <desc> child
+// of an element, or inserts a new <desc> after its <title>. An empty
+// description removes the <desc>. Elements that can not have a <desc> are
+// not changed.
+func SetAccessibleDescription(it Item, desc string) {
+	switch it := it.(type) {
+	case *Text:
+		setDesc(&it.Content, desc)
+	case *TSpan:
+		setDesc(&it.Content, desc)
+	case *TextPath:
+		setDesc(&it.Content, desc)
+	case interface{ node() *Node }:
+		setDesc(&it.node().Items, desc)
+	case interface{ shape() *shapeNode }:
+		setDesc(&it.shape().Items, desc)
+	}
+}
+
+// setTitle implements SetAccessibleName over the children of an element,
+// which are either items or text content
+func setTitle[T any](items *[]T, name string) {
+	for i, it := range *items {
+		if t, ok := any(it).(*Title); ok {
+			if name == "" {
+				*items = append((*items)[:i:i], (*items)[i+1:]...)
+			} else {
+				t.Content = name
+			}
+			return
+		}
+	}
+	if name != "" {
+		*items = append([]T{any(&Title{Content: name}).(T)}, *items...)
+	}
+}
+
+// setDesc implements SetAccessibleDescription over the children of an
+// element, which are either items or text content
+func setDesc[T any](items *[]T, desc string) {
+	at := 0
+	for i, it := range *items {
+		switch it := any(it).(type) {
+		case *Desc:
+			if desc == "" {
+				*items = append((*items)[:i:i], (*items)[i+1:]...)
+			} else {
+				it.Content = desc
+			}
+			return
+		case *Title:
+			if at == 0 {
+				at = i + 1
+			}
+		}
+	}
+	if desc != "" {
+		ret := append([]T{}, (*items)[:at]...)
+		ret = append(ret, any(&Desc{Content: desc}).(T))
+		*items = append(ret, (*items)[at:]...)
+	}
+}
+
+// AccessibleName returns the accessible name of the element, see the
+// AccessibleName function
+func (n *Node) AccessibleName() string {
+	return AccessibleName(n)
+}
+
+// AccessibleDescription returns the accessible description of the element,
+// see the AccessibleDescription function
+func (n *Node) AccessibleDescription() string {
+	return AccessibleDescription(n)
+}
+
+// SetAccessibleName sets the accessible name of the element, see the
+// SetAccessibleName function
+func (n *Node) SetAccessibleName(name string) {
+	SetAccessibleName(n, name)
+}
+
+// SetAccessibleDescription sets the accessible description of the element,
+// see the SetAccessibleDescription function
+func (n *Node) SetAccessibleDescription(desc string) {
+	SetAccessibleDescription(n, desc)
+}
@@ -0,0 +1,104 @@
+package svg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDescriptiveElements(t *testing.T) {
+	metadata := `
+			<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:dc='http://purl.org/dc/elements/1.1/'>
+				<!-- generated -->
+				<dc:title>Check &amp; confirm</dc:title>
+				<dc:format/>
+			</rdf:RDF>
+		`
+	data := `<svg viewBox="0 0 24 24">
+		<title id="t">  Check
+			mark </title>
+		<desc>Confirms &lt;the&gt; action</desc>
+		<metadata id="m">` + metadata + `</metadata>
+		<g id="g"><title>Tick</title><path d="M4,12L10,18L20,6"/></g>
+		<g id="plain"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.AccessibleName(); got != "Check mark" {
+		t.Errorf("accessible name = %q", got)
+	}
+	if got := doc.AccessibleDescription(); got != "Confirms <the> action" {
+		t.Errorf("accessible description = %q", got)
+	}
+	if got := doc.ByID("m").(*Metadata).Content; got != metadata {
+		t.Errorf("metadata = %q, want %q", got, metadata)
+	}
+	if got := doc.ByID("g").(*Group).AccessibleName(); got != "Tick" {
+		t.Errorf("group accessible name = %q", got)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+	if !strings.Contains(out, "<metadata id=\"m\">"+metadata+"</metadata>") {
+		t.Errorf("metadata is not preserved:\n%s", out)
+	}
+
+	plain := doc.ByID("plain").(*Group)
+	plain.SetAccessibleDescription("described")
+	plain.SetAccessibleName("named")
+	plain.SetAccessibleDescription("described again")
+	if len(plain.Items) != 2 || plain.AccessibleName() != "named" ||
+		plain.AccessibleDescription() != "described again" {
+		t.Errorf("items = %#v", plain.Items)
+	}
+	if _, ok := plain.Items[0].(*Title); !ok {
+		t.Errorf("title is not the first child")
+	}
+	plain.SetAccessibleName("")
+	plain.SetAccessibleDescription("")
+	if len(plain.Items) != 0 {
+		t.Errorf("items = %#v", plain.Items)
+	}
+}
+
+func TestAccessibleShapesAndText(t *testing.T) {
+	doc, err := Parse(`<svg>
+		<rect id="r" width="1" height="1"><title>Box</title><desc>A square</desc></rect>
+		<circle id="c" r="1" aria-label=" Dot "/>
+		<text id="t"><title>Greeting</title>hello</text>
+	</svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := doc.ByID("r")
+	if AccessibleName(r) != "Box" || AccessibleDescription(r) != "A square" {
+		t.Errorf("rect name = %q, description = %q", AccessibleName(r), AccessibleDescription(r))
+	}
+	if got := AccessibleName(doc.ByID("c")); got != "Dot" {
+		t.Errorf("circle name = %q", got)
+	}
+	txt := doc.ByID("t").(*Text)
+	if got := AccessibleName(txt); got != "Greeting" {
+		t.Errorf("text name = %q", got)
+	}
+
+	SetAccessibleName(txt, "Hi")
+	SetAccessibleDescription(txt, "says hello")
+	if len(txt.Content) != 3 || AccessibleName(txt) != "Hi" || AccessibleDescription(txt) != "says hello" {
+		t.Errorf("text content = %#v", txt.Content)
+	}
+	if _, ok := txt.Content[1].(*Desc); !ok {
+		t.Errorf("desc is not placed after the title")
+	}
+
+	c := doc.ByID("c").(*Circle)
+	SetAccessibleDescription(c, "round")
+	if len(c.Items) != 1 || AccessibleDescription(c) != "round" {
+		t.Errorf("circle items = %#v", c.Items)
+	}
+}
@@ -0,0 +1,239 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	xg "github.com/adnsv/xmlgo"
+)
+
+// ParseError describes an element or an attribute that can not be read,
+// use errors.As to obtain it from the error returned by Parse. It also
+// describes the elements and the attributes reported to WithWarnings.
+type ParseError struct {
+	Element string // path of the element, such as svg/g#layer/path, empty if not known
+	Attr    string // name of the attribute, empty if not known
+	Line    int    // 1-based, 0 if the location is not known
+	Column  int    // 1-based, in characters
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	sb := strings.Builder{}
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, "%d:%d: ", e.Line, e.Column)
+	}
+	if e.Element != "" {
+		sb.WriteString(e.Element)
+		if e.Attr != "" {
+			sb.WriteString(" @" + e.Attr)
+		}
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// PathError describes invalid path data, the offset is the byte position
+// within the path data string
+type PathError struct {
+	Offset int
+	Msg    string
+	Err    error // the cause, if any
+}
+
+func (e *PathError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s at %d, %s", e.Msg, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("%s at %d", e.Msg, e.Offset)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// xmlError is the cause of *ParseError for malformed XML, it wraps the error
+// of the XML parser without repeating its location
+type xmlError struct {
+	msg string
+	err error
+}
+
+func (e *xmlError) Error() string {
+	return e.msg
+}
+
+func (e *xmlError) Unwrap() error {
+	return e.err
+}
+
+// syntaxError converts an error of the XML parser within the content of the
+// element into *ParseError, errors that are already converted are returned
+// as is
+func (x *xgsourcer) syntaxError(err error) error {
+	return x.ctx.syntaxError(x.elementPath(), err)
+}
+
+// syntaxError converts an error of the XML parser into *ParseError that
+// refers to the element, the location is taken from the message, as the
+// error type of the parser is not exported
+func (ctx *parseContext) syntaxError(element string, err error) error {
+	var pe *ParseError
+	if err == nil || errors.As(err, &pe) {
+		return err
+	}
+	pe = &ParseError{Element: element, Err: err}
+	s := err.Error()
+	if n, _ := fmt.Sscanf(s, "xml parser [%d:%d]:", &pe.Line, &pe.Column); n == 2 {
+		pe.Err = &xmlError{msg: strings.TrimSpace(s[strings.Index(s, "]:")+2:]), err: err}
+	} else {
+		pe.Line, pe.Column = 0, 0
+	}
+	return pe
+}
+
+// fail converts an error of the reader into *ParseError that refers to the
+// element, and to the attribute that was accessed last. Errors of nested
+// elements are already converted, these are returned as is.
+func (x *xgsourcer) fail(err error) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return err
+	}
+	return x.makeError(x.lastAttr, err)
+}
+
+// reject handles an invalid value of an attribute. In lenient mode the value
+// is reported as a warning and nil is returned, so that the reader can go on.
+func (x *xgsourcer) reject(attr string, err error) error {
+	pe := x.makeError(attr, err)
+	if x.ctx.opts.mode != ParseLenient {
+		return pe
+	}
+	x.warn(pe)
+	return nil
+}
+
+// recover is called when the reader fails, in lenient mode the attribute
+// that was accessed last is dropped, and true is returned if the element
+// can be read again without it
+func (x *xgsourcer) recover(err error) bool {
+	var pe *ParseError
+	if x.ctx.opts.mode != ParseLenient || x.consumed || x.lastAttr == "" ||
+		x.rejected[x.lastAttr] || errors.As(err, &pe) {
+		return false
+	}
+	// the warnings of the failed attempt are reported again by the retry
+	x.pending = append(x.pending[:x.kept], x.makeError(x.lastAttr, err))
+	x.kept = len(x.pending)
+	if x.rejected == nil {
+		x.rejected = map[string]bool{}
+	}
+	x.rejected[x.lastAttr] = true
+	x.lastAttr = ""
+	x.usedAttrs = map[string]bool{}
+	x.usedProps = map[string]bool{}
+	x.sources = nil
+	return true
+}
+
+// dropElement reports the element as dropped
+func (x *xgsourcer) dropElement() {
+	x.warn(x.makeError("", ErrUnsupportedElement))
+	x.flush()
+}
+
+func (x *xgsourcer) warnAttr(attr string, err error) {
+	x.warn(x.makeError(attr, err))
+}
+
+// warn reports a warning, the warnings are held until the element is read
+// successfully or its content is reached, as the element may be read again
+// in lenient mode
+func (x *xgsourcer) warn(pe *ParseError) {
+	x.pending = append(x.pending, pe)
+	if x.consumed {
+		x.flush()
+	}
+}
+
+// flush passes the pending warnings to the collector
+func (x *xgsourcer) flush() {
+	if x.ctx.opts.warn != nil {
+		for _, pe := range x.pending {
+			x.ctx.opts.warn(pe)
+		}
+	}
+	x.pending, x.kept = nil, 0
+}
+
+func (x *xgsourcer) makeError(attr string, err error) *ParseError {
+	pe := &ParseError{Element: x.elementPath(), Attr: attr, Err: err}
+	pos := x.ctx.tagPos(x.index)
+	if a := x.attrToken(attr); a != nil {
+		pos = a.SrcPos
+		if q := strings.IndexAny(a.Raw, `"'`); q >= 0 {
+			pos += q + 1
+			var pathErr *PathError
+			if errors.As(err, &pathErr) {
+				pos += rawOffset(string(a.Value), pathErr.Offset)
+			}
+		}
+	}
+	if pos >= 0 {
+		pe.Line, pe.Column = xg.CalcLocation(x.ctx.in, pos)
+		pe.Line++
+		pe.Column++
+	}
+	return pe
+}
+
+func (x *xgsourcer) attrToken(name string) *xg.Token {
+	if name == "" {
+		return nil
+	}
+	for _, a := range x.aa {
+		if string(a.Name) == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// elementPath returns the tags of the element and its ancestors separated
+// with slashes, the tags are followed by ids where available
+func (x *xgsourcer) elementPath() string {
+	ss := []string{}
+	for el := x.el; el != nil; el = el.parent {
+		s := el.tag
+		if el.id != "" {
+			s += "#" + el.id
+		}
+		ss = append([]string{s}, ss...)
+	}
+	return strings.Join(ss, "/")
+}
+
+// rawOffset converts a byte offset within the unescaped value of an
+// attribute to the offset within its escaped markup
+func rawOffset(raw string, offset int) int {
+	i, n := 0, 0
+	for i < len(raw) && n < offset {
+		if raw[i] == '&' {
+			if sc := strings.IndexByte(raw[i:], ';'); sc > 0 {
+				n += len(xg.RawString(raw[i : i+sc+1]).Unscrambled())
+				i += sc + 1
+				continue
+			}
+		}
+		i++
+		n++
+	}
+	return i
+}
@@ -0,0 +1,273 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		data    string
+		element string
+		attr    string
+		line    int
+		column  int
+	}{
+		{
+			data: `<svg>
+	<g id="layer">
+		<path fill="#12" d="M0 0"/>
+	</g>
+</svg>`,
+			element: "svg/g#layer/path", attr: "fill", line: 3, column: 15,
+		},
+		{
+			data: `<svg>
+  <path d="M0 0 L10 10 Z Q1"/>
+</svg>`,
+			element: "svg/path", attr: "d", line: 2, column: 26,
+		},
+		{
+			data: `<svg>
+  <path d='M0&#32;0&#x20;L 1 X'/>
+</svg>`,
+			element: "svg/path", attr: "d", line: 2, column: 26,
+		},
+		{
+			// skipped and raw content must not disturb the positions of
+			// the elements that follow
+			data: `<svg>
+  <metadata><rdf:RDF><cc:Work/></rdf:RDF></metadata>
+  <linearGradient><stop offset="0"/><animate/></linearGradient>
+  <filter><feMerge><feMergeNode/><set/></feMerge></filter>
+  <unknown><a><b/></a></unknown>
+  <rect/>
+  <circle fill="x"/>
+</svg>`,
+			element: "svg/circle", attr: "fill", line: 7, column: 17,
+		},
+		{
+			data: `<svg viewBox="0 0 1 1">
+  <text>hello <tspan
+     x="1 2 q">world</tspan></text>
+</svg>`,
+			element: "svg/text/tspan", attr: "x", line: 3, column: 9,
+		},
+	}
+	for _, tt := range tests {
+		// invalid path data is reported as a warning
+		var pe *ParseError
+		_, err := Parse(tt.data, WithWarnings(func(w *ParseError) {
+			if pe == nil {
+				pe = w
+			}
+		}))
+		if err != nil && !errors.As(err, &pe) {
+			t.Errorf("%s: got %v, want *ParseError", tt.element, err)
+			continue
+		}
+		if pe == nil {
+			t.Errorf("%s: no error or warning", tt.element)
+			continue
+		}
+		if pe.Element != tt.element || pe.Attr != tt.attr || pe.Line != tt.line || pe.Column != tt.column {
+			t.Errorf("got %s @%s %d:%d, want %s @%s %d:%d (%s)", pe.Element, pe.Attr, pe.Line, pe.Column,
+				tt.element, tt.attr, tt.line, tt.column, err)
+		}
+	}
+
+	_, err := Parse(`<svg><rect fill-opacity="half"/></svg>`)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("cause is not wrapped: %v", err)
+	}
+
+	var warning error
+	doc, err := Parse(`<svg><path d="M0 0 1"/></svg>`, WithWarnings(func(w *ParseError) {
+		warning = w
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := doc.Items[0].(*Path).D; d != "M0 0 1" {
+		t.Errorf("invalid path data is not kept: %q", d)
+	}
+	var pathErr *PathError
+	if !errors.As(warning, &pathErr) || pathErr.Offset != 0 {
+		t.Errorf("path error = %v", warning)
+	}
+	if got, want := warning.Error(), "1:15: svg/path @d: invalid d: invalid num arguments in 'M' command at 0"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	tests := []struct {
+		data    string
+		element string
+		line    int
+		column  int
+		msg     string
+	}{
+		{`<svg fill="red"><rect width="1"/>`, "svg", 1, 34, "1:34: svg: unexpected end of file"},
+		{"<svg>\n  <g><rect></g></svg>", "svg/g/rect", 2, 12, "2:12: svg/g/rect: mismatching tag"},
+		{"", "", 1, 1, "1:1: unexpected end of file"},
+		{"\n<html/>", "html", 2, 1, "2:1: html: root tag must be 'svg'"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.data)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%q: got %v, want *ParseError", tt.data, err)
+			continue
+		}
+		if pe.Element != tt.element || pe.Attr != "" || pe.Line != tt.line || pe.Column != tt.column ||
+			pe.Error() != tt.msg {
+			t.Errorf("%q: got %s @%s %d:%d %q, want %s %d:%d %q", tt.data, pe.Element, pe.Attr,
+				pe.Line, pe.Column, pe.Error(), tt.element, tt.line, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestParseModes(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24">
+  <style>.a { fill: zzz }</style>
+  <rect id="r1" fill="bogus" width="10"/>
+  <rect id="r2" style="fill: red; stroke: nope" fill="blue"/>
+  <rect id="r3" class="a" fill="green"/>
+  <linearGradient id="lg" gradientTransform="rotate(x)"><stop offset="0"/><animate/></linearGradient>
+  <path id="p" d="M0 0 Q" stroke="red"/>
+  <marker id="m" orient="sideways"><circle r="1"/></marker>
+  <title>icon<b>bold</b></title>
+</svg>`
+
+	if _, err := Parse(data); err == nil {
+		t.Fatalf("strict mode: expected an error")
+	}
+
+	warnings := []*ParseError{}
+	collect := WithWarnings(func(w *ParseError) {
+		warnings = append(warnings, w)
+	})
+	doc, err := Parse(data, WithParseMode(ParseLenient), collect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r1 := doc.ByID("r1").(*Rect)
+	if r1.Fill != nil || r1.Width != "10" {
+		t.Errorf("r1 = %+v", r1)
+	}
+	r2 := doc.ByID("r2").(*Rect)
+	if r2.Fill == nil || r2.Fill.Color != (RGB{0xff, 0, 0}) || r2.Stroke != nil {
+		t.Errorf("r2 fill = %v, stroke = %v", r2.Fill, r2.Stroke)
+	}
+	r3 := doc.ByID("r3").(*Rect)
+	if r3.Fill == nil || r3.Fill.Color != (RGB{0, 0x80, 0}) {
+		t.Errorf("r3 fill = %v", r3.Fill)
+	}
+	lg := doc.ByID("lg").(*LinearGradient)
+	if lg.GradientTransform != nil || len(lg.Stops) != 1 || len(lg.Items) != 1 {
+		t.Errorf("gradient = %+v", lg)
+	}
+	if p := doc.ByID("p").(*Path); p.D != "M0 0 Q" || p.Stroke == nil {
+		t.Errorf("path = %+v", p)
+	}
+	if m := doc.ByID("m").(*Marker); m.Orient != nil || len(m.Items) != 1 {
+		t.Errorf("marker = %+v", m)
+	}
+
+	type warning struct {
+		element string
+		attr    string
+		line    int
+	}
+	want := []warning{
+		{"svg/rect#r1", "fill", 3},
+		{"svg/rect#r2", "style", 4},
+		{"svg/rect#r3", "", 5},
+		{"svg/linearGradient#lg", "gradientTransform", 6},
+		{"svg/path#p", "d", 7},
+		{"svg/marker#m", "orient", 8},
+		{"svg/title/b", "", 9},
+	}
+	got := []warning{}
+	for _, w := range warnings {
+		got = append(got, warning{w.Element, w.Attr, w.Line})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %v, want %v", got, want)
+	}
+	if !errors.Is(warnings[6], ErrUnsupportedElement) {
+		t.Errorf("dropped element warning = %v", warnings[6])
+	}
+
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	for _, s := range []string{"bogus", "nope", "rotate(x)", "sideways"} {
+		if strings.Contains(buf.String(), s) {
+			t.Errorf("dropped value %q is written:\n%s", s, buf.String())
+		}
+	}
+
+	// dropped elements are reported in strict mode as well
+	warnings = nil
+	if _, err = Parse(`<svg><desc>a<set/></desc></svg>`, collect); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Element != "svg/desc/set" {
+		t.Errorf("strict mode warnings = %v", warnings)
+	}
+}
+
+func TestLenientRetryWarnings(t *testing.T) {
+	data := `<svg>
+  <path d="M0 0 L" style="fill:bogus" transform="bad(1)"/>
+  <text dominant-baseline="bogus" transform="bad(1)">a</text>
+</svg>`
+	got := map[string]int{}
+	_, err := Parse(data, WithParseMode(ParseLenient), WithWarnings(func(w *ParseError) {
+		got[w.Element+" @"+w.Attr]++
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		"svg/path @d":                 1,
+		"svg/path @style":             1,
+		"svg/path @transform":         1,
+		"svg/text @dominant-baseline": 1,
+		"svg/text @transform":         1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %v, want %v", got, want)
+	}
+}
+
+func TestCompactArcFlags(t *testing.T) {
+	compact, err := ParsePath("M0 0a1 1 0 0110 10z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spaced, err := ParsePath("M0 0a1 1 0 0 1 10 10z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(compact, spaced) {
+		t.Errorf("compact arc flags: got %v, want %v", compact, spaced)
+	}
+	if _, err = ParsePath("M0 0a1 1 0 2 1 10 10"); err == nil {
+		t.Errorf("expected an error for invalid arc flag")
+	}
+
+	warnings := 0
+	_, err = Parse(`<svg><path d="M0 0a1 1 0 0110 10z"/></svg>`, WithWarnings(func(*ParseError) {
+		warnings++
+	}))
+	if err != nil || warnings != 0 {
+		t.Errorf("compact arc flags: err = %v, %d warnings", err, warnings)
+	}
+}
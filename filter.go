@@ -0,0 +1,656 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Filter implements SVG <filter> element
+//
+// The primitives are applied in document order, the input of each one is
+// selected with its in and in2 attributes, either by the result name of a
+// preceding primitive, or by a keyword such as SourceGraphic. Primitives
+// that are not supported are kept as Unknown items.
+type Filter struct {
+	item
+	Units          GradientUnits // filterUnits, objectBoundingBox when unspecified
+	PrimitiveUnits GradientUnits // primitiveUnits, userSpaceOnUse when unspecified
+	X              Coordinate
+	Y              Coordinate
+	Width          Length
+	Height         Length
+	Primitives     []Item
+}
+
+func (f *Filter) children() []Item {
+	return f.Primitives
+}
+
+func (f *Filter) read(src sourcer) (err error) {
+	err = f.item.read(src)
+	if err != nil {
+		return
+	}
+	if v, exists := src.Attr("filterUnits"); exists {
+		if err = f.Units.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid filterUnits: %w", err)
+		}
+	}
+	if v, exists := src.Attr("primitiveUnits"); exists {
+		if err = f.PrimitiveUnits.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid primitiveUnits: %w", err)
+		}
+	}
+	if s, ok := src.Attr("x"); ok {
+		f.X = Coordinate(s)
+	}
+	if s, ok := src.Attr("y"); ok {
+		f.Y = Coordinate(s)
+	}
+	if s, ok := src.Attr("width"); ok {
+		f.Width = Length(s)
+	}
+	if s, ok := src.Attr("height"); ok {
+		f.Height = Length(s)
+	}
+	return src.ForEachContent(func(tag string, cs sourcer) error {
+		var it reader
+		switch tag {
+		case "feGaussianBlur":
+			it = &FeGaussianBlur{}
+		case "feOffset":
+			it = &FeOffset{}
+		case "feFlood":
+			it = &FeFlood{}
+		case "feComposite":
+			it = &FeComposite{}
+		case "feMerge":
+			it = &FeMerge{}
+		case "feColorMatrix":
+			it = &FeColorMatrix{}
+		case "feBlend":
+			it = &FeBlend{}
+		case "feDropShadow":
+			it = &FeDropShadow{}
+		case "title":
+			it = &Title{}
+		case "desc":
+			it = &Desc{}
+		default:
+			it = &Unknown{Tag: tag}
+		}
+		if err := readItem(it, cs); err != nil {
+			return err
+		}
+		f.Primitives = append(f.Primitives, it)
+		return nil
+	}, nil, func(text string) error {
+		f.Primitives = append(f.Primitives, &Comment{Text: text})
+		return nil
+	})
+}
+
+func (f *Filter) write(tgt targeter) {
+	f.item.write(tgt)
+	tgt.Attr("filterUnits", f.Units.String())
+	tgt.Attr("primitiveUnits", f.PrimitiveUnits.String())
+	tgt.Attr("x", string(f.X))
+	tgt.Attr("y", string(f.Y))
+	tgt.Attr("width", string(f.Width))
+	tgt.Attr("height", string(f.Height))
+	for _, it := range f.Primitives {
+		tag := ""
+		switch it := it.(type) {
+		case *FeGaussianBlur:
+			tag = "feGaussianBlur"
+		case *FeOffset:
+			tag = "feOffset"
+		case *FeFlood:
+			tag = "feFlood"
+		case *FeComposite:
+			tag = "feComposite"
+		case *FeMerge:
+			tag = "feMerge"
+		case *FeColorMatrix:
+			tag = "feColorMatrix"
+		case *FeBlend:
+			tag = "feBlend"
+		case *FeDropShadow:
+			tag = "feDropShadow"
+		case *Title:
+			tag = "title"
+		case *Desc:
+			tag = "desc"
+		case *Unknown:
+			tag = it.Tag
+		case *Comment:
+			it.write(tgt)
+			continue
+		default:
+			panic("unknown filter primitive")
+		}
+		tgt.Child(tag, it.write)
+	}
+}
+
+// Region returns the effective filter region, the attributes that are not
+// specified default to -10%, -10%, 120% and 120% respectively. The values
+// are interpreted according to Units.
+func (f *Filter) Region() (x, y Coordinate, width, height Length) {
+	x, y, width, height = f.X, f.Y, f.Width, f.Height
+	if x == "" {
+		x = "-10%"
+	}
+	if y == "" {
+		y = "-10%"
+	}
+	if width == "" {
+		width = "120%"
+	}
+	if height == "" {
+		height = "120%"
+	}
+	return
+}
+
+// FilterOf returns the <filter> element referenced by the filter property of
+// it, or nil if the property is not specified or the reference can not be
+// resolved
+func (svg *Svg) FilterOf(it Item) *Filter {
+	p, ok := it.(presenter)
+	if !ok {
+		return nil
+	}
+	f, _ := svg.referenced(p.presentation().Filter).(*Filter)
+	return f
+}
+
+// FilterPrimitive holds attributes that are common to filter primitive
+// elements: the subregion and the name of the result
+type FilterPrimitive struct {
+	item
+	X      Coordinate
+	Y      Coordinate
+	Width  Length
+	Height Length
+	Result string
+}
+
+func (fp *FilterPrimitive) read(src sourcer) (err error) {
+	err = fp.item.read(src)
+	if err != nil {
+		return
+	}
+	if s, ok := src.Attr("x"); ok {
+		fp.X = Coordinate(s)
+	}
+	if s, ok := src.Attr("y"); ok {
+		fp.Y = Coordinate(s)
+	}
+	if s, ok := src.Attr("width"); ok {
+		fp.Width = Length(s)
+	}
+	if s, ok := src.Attr("height"); ok {
+		fp.Height = Length(s)
+	}
+	fp.Result, _ = src.Attr("result")
+	return
+}
+
+func (fp *FilterPrimitive) write(tgt targeter) {
+	fp.item.write(tgt)
+	tgt.Attr("x", string(fp.X))
+	tgt.Attr("y", string(fp.Y))
+	tgt.Attr("width", string(fp.Width))
+	tgt.Attr("height", string(fp.Height))
+	tgt.Attr("result", fp.Result)
+}
+
+// readNumber reads an optional numeric attribute
+func readNumber(src sourcer, name string) (v *float64, err error) {
+	if s, ok := src.Attr(name); ok {
+		v, err = ParseNumber(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return
+}
+
+func writeNumber(tgt targeter, name string, v *float64) {
+	if v != nil {
+		tgt.Attr(name, formatNumber(*v))
+	}
+}
+
+// Flood holds flood-color and flood-opacity properties of <feFlood> and
+// <feDropShadow> elements
+type Flood struct {
+	FloodColor   *Paint   // black if not specified
+	FloodOpacity *float64 // 1 if not specified
+}
+
+var floodProperties = []string{
+	"flood-color",
+	"flood-opacity",
+}
+
+func (fl *Flood) set(name, v string) (known bool, err error) {
+	known = true
+	switch name {
+	case "flood-color":
+		fl.FloodColor, err = parseColorPaint(v)
+	case "flood-opacity":
+		fl.FloodOpacity, err = ParseOpacity(v)
+	default:
+		known = false
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return
+}
+
+func (fl *Flood) read(src sourcer) error {
+	return readProperties(src, floodProperties, fl.set)
+}
+
+func (fl *Flood) write(tgt targeter) {
+	if fl.FloodColor != nil {
+		tgt.Property("flood-color", fl.FloodColor.String())
+	}
+	if fl.FloodOpacity != nil {
+		tgt.Property("flood-opacity", formatNumber(*fl.FloodOpacity))
+	}
+}
+
+// FeGaussianBlur implements SVG <feGaussianBlur> filter primitive
+type FeGaussianBlur struct {
+	FilterPrimitive
+	In           string
+	StdDeviation NumberList // one or two values, for x and y
+	EdgeMode     string
+}
+
+func (fe *FeGaussianBlur) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	fe.In, _ = src.Attr("in")
+	if s, ok := src.Attr("stdDeviation"); ok {
+		fe.StdDeviation, err = ParseNumberList(s)
+		if err != nil {
+			return fmt.Errorf("invalid stdDeviation: %w", err)
+		}
+	}
+	fe.EdgeMode, _ = src.Attr("edgeMode")
+	return
+}
+
+func (fe *FeGaussianBlur) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	tgt.Attr("in", fe.In)
+	tgt.Attr("stdDeviation", fe.StdDeviation.String())
+	tgt.Attr("edgeMode", fe.EdgeMode)
+}
+
+// FeOffset implements SVG <feOffset> filter primitive
+type FeOffset struct {
+	FilterPrimitive
+	In string
+	Dx *float64
+	Dy *float64
+}
+
+func (fe *FeOffset) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	fe.In, _ = src.Attr("in")
+	if fe.Dx, err = readNumber(src, "dx"); err != nil {
+		return
+	}
+	fe.Dy, err = readNumber(src, "dy")
+	return
+}
+
+func (fe *FeOffset) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	tgt.Attr("in", fe.In)
+	writeNumber(tgt, "dx", fe.Dx)
+	writeNumber(tgt, "dy", fe.Dy)
+}
+
+// FeFlood implements SVG <feFlood> filter primitive
+type FeFlood struct {
+	FilterPrimitive
+	Flood
+}
+
+func (fe *FeFlood) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	return fe.Flood.read(src)
+}
+
+func (fe *FeFlood) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	fe.Flood.write(tgt)
+}
+
+// CompositeOperator implements operator attribute value of <feComposite>
+type CompositeOperator int
+
+const (
+	CompositeOver = CompositeOperator(iota)
+	CompositeIn
+	CompositeOut
+	CompositeAtop
+	CompositeXor
+	CompositeArithmetic
+	CompositeLighter
+)
+
+var compositeOperatorNames = []string{
+	"over",
+	"in",
+	"out",
+	"atop",
+	"xor",
+	"arithmetic",
+	"lighter",
+}
+
+func (co CompositeOperator) String() string {
+	if co < 0 || int(co) >= len(compositeOperatorNames) {
+		return ""
+	}
+	return compositeOperatorNames[co]
+}
+
+func (co *CompositeOperator) UnmarshalText(text []byte) error {
+	s := string(text)
+	for i, n := range compositeOperatorNames {
+		if s == n {
+			*co = CompositeOperator(i)
+			return nil
+		}
+	}
+	return errors.New("invalid operator value")
+}
+
+// FeComposite implements SVG <feComposite> filter primitive
+type FeComposite struct {
+	FilterPrimitive
+	In       string
+	In2      string
+	Operator *CompositeOperator // over if not specified
+	K1       *float64
+	K2       *float64
+	K3       *float64
+	K4       *float64
+}
+
+func (fe *FeComposite) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	fe.In, _ = src.Attr("in")
+	fe.In2, _ = src.Attr("in2")
+	if s, ok := src.Attr("operator"); ok {
+		op := CompositeOver
+		if err = op.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid operator: %w", err)
+		}
+		fe.Operator = &op
+	}
+	for _, k := range []struct {
+		name string
+		v    **float64
+	}{{"k1", &fe.K1}, {"k2", &fe.K2}, {"k3", &fe.K3}, {"k4", &fe.K4}} {
+		if *k.v, err = readNumber(src, k.name); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (fe *FeComposite) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	tgt.Attr("in", fe.In)
+	tgt.Attr("in2", fe.In2)
+	if fe.Operator != nil {
+		tgt.Attr("operator", fe.Operator.String())
+	}
+	writeNumber(tgt, "k1", fe.K1)
+	writeNumber(tgt, "k2", fe.K2)
+	writeNumber(tgt, "k3", fe.K3)
+	writeNumber(tgt, "k4", fe.K4)
+}
+
+// FeMerge implements SVG <feMerge> filter primitive, which composites its
+// inputs on top of each other
+type FeMerge struct {
+	FilterPrimitive
+	Nodes []*FeMergeNode
+
+	// extraChildren keeps the children other than <feMergeNode>, such as
+	// <animate>
+	extraChildren
+}
+
+func (fe *FeMerge) children() []Item {
+	ret := make([]Item, 0, len(fe.Nodes)+len(fe.Items))
+	for _, n := range fe.Nodes {
+		ret = append(ret, n)
+	}
+	return append(ret, fe.Items...)
+}
+
+func (fe *FeMerge) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	return src.ForEachContent(func(tag string, cs sourcer) error {
+		if tag != "feMergeNode" {
+			return fe.readExtra(tag, cs, len(fe.Nodes))
+		}
+		n := &FeMergeNode{}
+		if err := readItem(n, cs); err != nil {
+			return err
+		}
+		fe.Nodes = append(fe.Nodes, n)
+		return nil
+	}, nil, func(text string) error {
+		return fe.readComment(text, len(fe.Nodes))
+	})
+}
+
+func (fe *FeMerge) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	fe.writeAround(tgt, len(fe.Nodes), func(i int) {
+		tgt.Child("feMergeNode", fe.Nodes[i].write)
+	})
+}
+
+// FeMergeNode implements SVG <feMergeNode> element, an input of <feMerge>
+type FeMergeNode struct {
+	item
+	In string
+}
+
+func (n *FeMergeNode) read(src sourcer) (err error) {
+	err = n.item.read(src)
+	if err != nil {
+		return
+	}
+	n.In, _ = src.Attr("in")
+	return
+}
+
+func (n *FeMergeNode) write(tgt targeter) {
+	n.item.write(tgt)
+	tgt.Attr("in", n.In)
+}
+
+// ColorMatrixType implements type attribute value of <feColorMatrix>
+type ColorMatrixType int
+
+const (
+	ColorMatrixMatrix = ColorMatrixType(iota)
+	ColorMatrixSaturate
+	ColorMatrixHueRotate
+	ColorMatrixLuminanceToAlpha
+)
+
+var colorMatrixTypeNames = []string{
+	"matrix",
+	"saturate",
+	"hueRotate",
+	"luminanceToAlpha",
+}
+
+func (ct ColorMatrixType) String() string {
+	if ct < 0 || int(ct) >= len(colorMatrixTypeNames) {
+		return ""
+	}
+	return colorMatrixTypeNames[ct]
+}
+
+func (ct *ColorMatrixType) UnmarshalText(text []byte) error {
+	s := string(text)
+	for i, n := range colorMatrixTypeNames {
+		if s == n {
+			*ct = ColorMatrixType(i)
+			return nil
+		}
+	}
+	return errors.New("invalid type value")
+}
+
+// FeColorMatrix implements SVG <feColorMatrix> filter primitive
+type FeColorMatrix struct {
+	FilterPrimitive
+	In     string
+	Type   *ColorMatrixType // matrix if not specified
+	Values NumberList
+}
+
+func (fe *FeColorMatrix) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	fe.In, _ = src.Attr("in")
+	if s, ok := src.Attr("type"); ok {
+		ct := ColorMatrixMatrix
+		if err = ct.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid type: %w", err)
+		}
+		fe.Type = &ct
+	}
+	if s, ok := src.Attr("values"); ok {
+		fe.Values, err = ParseNumberList(s)
+		if err != nil {
+			return fmt.Errorf("invalid values: %w", err)
+		}
+	}
+	return
+}
+
+func (fe *FeColorMatrix) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	tgt.Attr("in", fe.In)
+	if fe.Type != nil {
+		tgt.Attr("type", fe.Type.String())
+	}
+	tgt.Attr("values", fe.Values.String())
+}
+
+// blendModes lists the values of mode attribute of <feBlend>
+var blendModes = []string{
+	"normal", "multiply", "screen", "overlay", "darken", "lighten",
+	"color-dodge", "color-burn", "hard-light", "soft-light", "difference",
+	"exclusion", "hue", "saturation", "color", "luminosity",
+}
+
+// FeBlend implements SVG <feBlend> filter primitive
+type FeBlend struct {
+	FilterPrimitive
+	In   string
+	In2  string
+	Mode string // normal if not specified
+}
+
+func (fe *FeBlend) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	fe.In, _ = src.Attr("in")
+	fe.In2, _ = src.Attr("in2")
+	if s, ok := src.Attr("mode"); ok {
+		valid := false
+		for _, m := range blendModes {
+			valid = valid || s == m
+		}
+		if !valid {
+			return fmt.Errorf("invalid mode: %w", errors.New("invalid blend mode value"))
+		}
+		fe.Mode = s
+	}
+	return
+}
+
+func (fe *FeBlend) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	tgt.Attr("in", fe.In)
+	tgt.Attr("in2", fe.In2)
+	tgt.Attr("mode", fe.Mode)
+}
+
+// FeDropShadow implements SVG <feDropShadow> filter primitive
+type FeDropShadow struct {
+	FilterPrimitive
+	Flood
+	In           string
+	Dx           *float64 // 2 if not specified
+	Dy           *float64 // 2 if not specified
+	StdDeviation NumberList
+}
+
+func (fe *FeDropShadow) read(src sourcer) (err error) {
+	err = fe.FilterPrimitive.read(src)
+	if err != nil {
+		return
+	}
+	fe.In, _ = src.Attr("in")
+	if fe.Dx, err = readNumber(src, "dx"); err != nil {
+		return
+	}
+	if fe.Dy, err = readNumber(src, "dy"); err != nil {
+		return
+	}
+	if s, ok := src.Attr("stdDeviation"); ok {
+		fe.StdDeviation, err = ParseNumberList(s)
+		if err != nil {
+			return fmt.Errorf("invalid stdDeviation: %w", err)
+		}
+	}
+	return fe.Flood.read(src)
+}
+
+func (fe *FeDropShadow) write(tgt targeter) {
+	fe.FilterPrimitive.write(tgt)
+	fe.Flood.write(tgt)
+	tgt.Attr("in", fe.In)
+	writeNumber(tgt, "dx", fe.Dx)
+	writeNumber(tgt, "dy", fe.Dy)
+	tgt.Attr("stdDeviation", fe.StdDeviation.String())
+}
@@ -0,0 +1,117 @@
+package svg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24">
+		<defs>
+			<filter id="shadow" filterUnits="userSpaceOnUse" primitiveUnits="objectBoundingBox" x="0" y="0" width="24" height="24">
+				<feGaussianBlur in="SourceAlpha" stdDeviation="2 1" result="blur"/>
+				<feOffset in="blur" dx="1" dy="-1.5" result="offset"/>
+				<feFlood flood-color="#123456" style="flood-opacity:0.5" result="color"/>
+				<feComposite in="color" in2="offset" operator="arithmetic" k2="1" k3="0.5" result="shadow"/>
+				<feColorMatrix in="SourceGraphic" type="saturate" values="0.2" result="grey"/>
+				<feBlend in="grey" in2="shadow" mode="multiply" result="blended"/>
+				<feMerge>
+					<feMergeNode in="shadow"/>
+					<feMergeNode in="blended"/>
+				</feMerge>
+				<feTurbulence baseFrequency="0.05"/>
+			</filter>
+			<filter id="drop">
+				<feDropShadow dx="2" dy="3" stdDeviation="1" flood-color="red" flood-opacity="0.3"/>
+			</filter>
+		</defs>
+		<g id="g" filter="url(#shadow)">
+			<rect id="r1" width="24" height="24" style="filter: url(#drop)"/>
+		</g>
+		<rect id="r2" filter="none"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shadow := doc.ByID("shadow").(*Filter)
+	if shadow.Units != GradientUnitsUserSpaceOnUse || shadow.PrimitiveUnits != GradientUnitsObjectBoundingBox {
+		t.Errorf("units = %v, %v", shadow.Units, shadow.PrimitiveUnits)
+	}
+	if len(shadow.Primitives) != 8 {
+		t.Fatalf("primitives = %d", len(shadow.Primitives))
+	}
+	if fe := shadow.Primitives[0].(*FeGaussianBlur); fe.In != "SourceAlpha" || fe.Result != "blur" ||
+		!reflect.DeepEqual(fe.StdDeviation, NumberList{2, 1}) {
+		t.Errorf("feGaussianBlur = %+v", fe)
+	}
+	if fe := shadow.Primitives[1].(*FeOffset); fe.Dx == nil || *fe.Dx != 1 || fe.Dy == nil || *fe.Dy != -1.5 {
+		t.Errorf("feOffset = %+v", fe)
+	}
+	if fe := shadow.Primitives[2].(*FeFlood); fe.FloodColor == nil || fe.FloodColor.Color != (RGB{0x12, 0x34, 0x56}) ||
+		fe.FloodOpacity == nil || *fe.FloodOpacity != 0.5 {
+		t.Errorf("feFlood = %+v", fe)
+	}
+	if fe := shadow.Primitives[3].(*FeComposite); fe.In2 != "offset" || fe.Operator == nil ||
+		*fe.Operator != CompositeArithmetic || fe.K1 != nil || fe.K2 == nil || *fe.K2 != 1 {
+		t.Errorf("feComposite = %+v", fe)
+	}
+	if fe := shadow.Primitives[4].(*FeColorMatrix); fe.Type == nil || *fe.Type != ColorMatrixSaturate {
+		t.Errorf("feColorMatrix = %+v", fe)
+	}
+	if fe := shadow.Primitives[5].(*FeBlend); fe.Mode != "multiply" || fe.In2 != "shadow" {
+		t.Errorf("feBlend = %+v", fe)
+	}
+	if fe := shadow.Primitives[6].(*FeMerge); len(fe.Nodes) != 2 || fe.Nodes[1].In != "blended" {
+		t.Errorf("feMerge = %+v", fe)
+	}
+	if u, ok := shadow.Primitives[7].(*Unknown); !ok || u.Tag != "feTurbulence" {
+		t.Errorf("unsupported primitive = %+v", shadow.Primitives[7])
+	}
+
+	drop := doc.ByID("drop").(*Filter)
+	if x, y, w, h := drop.Region(); x != "-10%" || y != "-10%" || w != "120%" || h != "120%" {
+		t.Errorf("default region = %s %s %s %s", x, y, w, h)
+	}
+	for id, want := range map[string]*Filter{
+		"g":  shadow,
+		"r1": drop,
+		"r2": nil,
+	} {
+		if got := doc.FilterOf(doc.ByID(id)); got != want {
+			t.Errorf("FilterOf(%s) = %p, want %p", id, got, want)
+		}
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+
+	for _, tt := range []struct {
+		in  string
+		msg string
+	}{
+		{`<svg><filter filterUnits="bogus"/></svg>`, "invalid filterUnits"},
+		{`<svg><filter><feOffset dx="x"/></filter></svg>`, "invalid dx"},
+		{`<svg><filter><feComposite operator="plus"/></filter></svg>`, "invalid operator"},
+		{`<svg><filter><feColorMatrix type="sepia"/></filter></svg>`, "invalid type"},
+		{`<svg><filter><feBlend mode="add"/></filter></svg>`, "invalid mode"},
+		{`<svg><filter><feFlood flood-color="url(#g)"/></filter></svg>`, "invalid flood-color"},
+		{`<svg><rect filter="blur(2px)"/></svg>`, "invalid filter"},
+	} {
+		if _, err := Parse(tt.in); err == nil || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: error = %v, want %s", tt.in, err, tt.msg)
+		}
+	}
+
+	doc, err = Parse(`<svg><filter><feFlood flood-color="currentColor"/></filter></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := doc.Items[0].(*Filter).Primitives[0].(*FeFlood).FloodColor; c == nil || c.Kind != PaintKindCurrentColor {
+		t.Errorf("flood-color = %v", c)
+	}
+}
@@ -0,0 +1,140 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Image implements SVG <image> element
+type Image struct {
+	shapeNode
+	X                   Coordinate
+	Y                   Coordinate
+	Width               Length
+	Height              Length
+	PreserveAspectRatio *PreserveAspectRatio
+	Href                string
+}
+
+// NewImage returns an <image> element that embeds img as a PNG encoded data
+// URI, its width and height are set to the size of img in pixels
+func NewImage(img image.Image) (*Image, error) {
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	return &Image{
+		Width:  Length(strconv.Itoa(b.Dx())),
+		Height: Length(strconv.Itoa(b.Dy())),
+		Href:   "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+func (im *Image) read(src sourcer) (err error) {
+	err = im.Shape.read(src)
+	if err != nil {
+		return
+	}
+	if s, ok := src.Attr("x"); ok {
+		im.X = Coordinate(s)
+	}
+	if s, ok := src.Attr("y"); ok {
+		im.Y = Coordinate(s)
+	}
+	if s, ok := src.Attr("width"); ok {
+		im.Width = Length(s)
+	}
+	if s, ok := src.Attr("height"); ok {
+		im.Height = Length(s)
+	}
+	if s, ok := src.Attr("preserveAspectRatio"); ok {
+		im.PreserveAspectRatio, err = ParsePreserveAspectRatio(s)
+		if err != nil {
+			return fmt.Errorf("invalid preserveAspectRatio: %w", err)
+		}
+	}
+	im.Href = im.readHref(src)
+	return readChildren(src, &im.Items)
+}
+
+func (im *Image) write(tgt targeter) {
+	im.Shape.write(tgt)
+	tgt.Attr("x", string(im.X))
+	tgt.Attr("y", string(im.Y))
+	tgt.Attr("width", string(im.Width))
+	tgt.Attr("height", string(im.Height))
+	if im.PreserveAspectRatio != nil {
+		tgt.Attr("preserveAspectRatio", im.PreserveAspectRatio.String())
+	}
+	im.writeHref(tgt, im.Href)
+	writeChildren(tgt, im.Items)
+}
+
+// Decode decodes the image embedded with a data:image/png or data:image/jpeg
+// URI. Images that refer to external resources are not loaded.
+func (im *Image) Decode() (image.Image, error) {
+	mediaType, data, err := parseDataURI(im.Href)
+	if err != nil {
+		return nil, err
+	}
+	switch mediaType {
+	case "image/png":
+		return png.Decode(bytes.NewReader(data))
+	case "image/jpeg", "image/jpg":
+		return jpeg.Decode(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported image type '%s'", mediaType)
+	}
+}
+
+// parseDataURI extracts the media type and the content from a data URI,
+// such as data:image/png;base64,iVBORw0KGgo...
+func parseDataURI(s string) (mediaType string, data []byte, err error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 5 || !strings.EqualFold(s[:5], "data:") {
+		return "", nil, errors.New("not a data URI")
+	}
+	comma := strings.IndexByte(s, ',')
+	if comma < 0 {
+		return "", nil, errors.New("invalid data URI")
+	}
+	params := strings.Split(s[5:comma], ";")
+	mediaType = strings.ToLower(strings.TrimSpace(params[0]))
+	content := s[comma+1:]
+
+	isBase64 := false
+	for _, p := range params[1:] {
+		if strings.EqualFold(strings.TrimSpace(p), "base64") {
+			isBase64 = true
+		}
+	}
+	if !isBase64 {
+		content, err = url.PathUnescape(content)
+		return mediaType, []byte(content), err
+	}
+
+	// base64 content is often wrapped over several lines
+	content = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, content)
+	if u, e := url.PathUnescape(content); e == nil {
+		content = u
+	}
+	data, err = base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(content, "="))
+	}
+	return mediaType, data, err
+}
@@ -0,0 +1,78 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(1, 1, color.NRGBA{R: 0xff, A: 0xff})
+
+	im, err := NewImage(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im.Width != "3" || im.Height != "2" || !strings.HasPrefix(im.Href, "data:image/png;base64,") {
+		t.Errorf("image = %+v", im)
+	}
+	im.X, im.Y = "1", "2"
+	im.PreserveAspectRatio = &PreserveAspectRatio{Align: AlignXMinYMin, Slice: true}
+
+	doc := &Svg{}
+	doc.Items = append(doc.Items, im)
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	doc2, err := Parse(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	im2 := doc2.Items[0].(*Image)
+	if !reflect.DeepEqual(im, im2) {
+		t.Errorf("round trip mismatch, written document:\n%s", buf.String())
+	}
+
+	img, err := im2.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != src.Bounds() {
+		t.Errorf("decoded bounds = %v", img.Bounds())
+	}
+	if r, g, _, a := img.At(1, 1).RGBA(); r != 0xffff || g != 0 || a != 0xffff {
+		t.Errorf("decoded pixel = %v", img.At(1, 1))
+	}
+
+	// jpeg, with base64 content wrapped over lines as written by some editors
+	jb := bytes.Buffer{}
+	if err := jpeg.Encode(&jb, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	enc := base64.StdEncoding.EncodeToString(jb.Bytes())
+	doc, err = Parse(`<svg xmlns:xlink="http://www.w3.org/1999/xlink">
+		<image width="3" height="2" xlink:href="data:image/jpeg;base64,` + enc[:20] + "\n\t\t\t" + enc[20:] + `"/>
+		<image href="icon.png"/>
+		<image href="data:image/gif;base64,R0lGODlhAQABAAAAACw="/>
+	</svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img, err := doc.Items[0].(*Image).Decode(); err != nil || img.Bounds() != src.Bounds() {
+		t.Errorf("decoded jpeg %v, %v", img, err)
+	}
+	for _, it := range doc.Items[1:] {
+		if _, err := it.(*Image).Decode(); err == nil {
+			t.Errorf("%s: expected an error", it.(*Image).Href)
+		}
+	}
+
+	if _, err := Parse(`<svg><image preserveAspectRatio="fit"/></svg>`); err == nil {
+		t.Errorf("expected an error for invalid preserveAspectRatio")
+	}
+}
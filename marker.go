@@ -0,0 +1,400 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MarkerUnits implements SVG markerUnits attribute value
+type MarkerUnits int
+
+const (
+	MarkerUnitsUnspecified = MarkerUnits(iota)
+	MarkerUnitsStrokeWidth
+	MarkerUnitsUserSpaceOnUse
+)
+
+func (mu MarkerUnits) String() string {
+	switch mu {
+	case MarkerUnitsStrokeWidth:
+		return "strokeWidth"
+	case MarkerUnitsUserSpaceOnUse:
+		return "userSpaceOnUse"
+	default:
+		return ""
+	}
+}
+
+func (mu *MarkerUnits) Unmarshal(s string) error {
+	switch s {
+	case "strokeWidth":
+		*mu = MarkerUnitsStrokeWidth
+	case "userSpaceOnUse":
+		*mu = MarkerUnitsUserSpaceOnUse
+	default:
+		return errors.New("invalid markerUnits value")
+	}
+	return nil
+}
+
+// OrientKind selects how a marker is rotated
+type OrientKind int
+
+const (
+	OrientAngle            = OrientKind(iota) // fixed angle
+	OrientAuto                                // along the direction of the path
+	OrientAutoStartReverse                    // as auto, but reversed at the start of the path
+)
+
+// Orient implements SVG orient attribute value of <marker> element
+type Orient struct {
+	Kind  OrientKind
+	Angle float64 // in degrees, used with OrientAngle
+}
+
+// ParseOrient parses the content of orient attribute: auto,
+// auto-start-reverse, or an angle
+func ParseOrient(s string) (*Orient, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "auto":
+		return &Orient{Kind: OrientAuto}, nil
+	case "auto-start-reverse":
+		return &Orient{Kind: OrientAutoStartReverse}, nil
+	}
+	a, err := parseAngle(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Orient{Kind: OrientAngle, Angle: a}, nil
+}
+
+func (o *Orient) String() string {
+	switch o.Kind {
+	case OrientAuto:
+		return "auto"
+	case OrientAutoStartReverse:
+		return "auto-start-reverse"
+	default:
+		return formatNumber(o.Angle)
+	}
+}
+
+// Marker implements SVG <marker> element
+type Marker struct {
+	Node
+	Presentation
+	ViewBox             *ViewBoxValue
+	PreserveAspectRatio *PreserveAspectRatio
+	RefX                Coordinate
+	RefY                Coordinate
+	MarkerWidth         Length // 3 if not specified
+	MarkerHeight        Length // 3 if not specified
+	Units               MarkerUnits
+	Orient              *Orient // 0 if not specified
+}
+
+func (m *Marker) read(src sourcer) (err error) {
+	err = m.item.read(src)
+	if err != nil {
+		return
+	}
+	err = m.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	if s, ok := src.Attr("viewBox"); ok {
+		m.ViewBox, err = ParseViewBox(s)
+		if err != nil {
+			return fmt.Errorf("invalid viewBox: %w", err)
+		}
+	}
+	if s, ok := src.Attr("preserveAspectRatio"); ok {
+		m.PreserveAspectRatio, err = ParsePreserveAspectRatio(s)
+		if err != nil {
+			return fmt.Errorf("invalid preserveAspectRatio: %w", err)
+		}
+	}
+	if s, ok := src.Attr("refX"); ok {
+		m.RefX = Coordinate(s)
+	}
+	if s, ok := src.Attr("refY"); ok {
+		m.RefY = Coordinate(s)
+	}
+	if s, ok := src.Attr("markerWidth"); ok {
+		m.MarkerWidth = Length(s)
+	}
+	if s, ok := src.Attr("markerHeight"); ok {
+		m.MarkerHeight = Length(s)
+	}
+	if s, ok := src.Attr("markerUnits"); ok {
+		if err = m.Units.Unmarshal(s); err != nil {
+			return fmt.Errorf("invalid markerUnits: %w", err)
+		}
+	}
+	if s, ok := src.Attr("orient"); ok {
+		m.Orient, err = ParseOrient(s)
+		if err != nil {
+			return fmt.Errorf("invalid orient: %w", err)
+		}
+	}
+	return readChildren(src, &m.Items)
+}
+
+func (m *Marker) write(tgt targeter) {
+	m.item.write(tgt)
+	m.Presentation.write(tgt)
+	if m.ViewBox != nil {
+		tgt.Attr("viewBox", m.ViewBox.String())
+	}
+	if m.PreserveAspectRatio != nil {
+		tgt.Attr("preserveAspectRatio", m.PreserveAspectRatio.String())
+	}
+	tgt.Attr("refX", string(m.RefX))
+	tgt.Attr("refY", string(m.RefY))
+	tgt.Attr("markerWidth", string(m.MarkerWidth))
+	tgt.Attr("markerHeight", string(m.MarkerHeight))
+	tgt.Attr("markerUnits", m.Units.String())
+	if m.Orient != nil {
+		tgt.Attr("orient", m.Orient.String())
+	}
+	m.writeItems(tgt)
+}
+
+// InstanceTransform returns the transform from the marker content
+// coordinate system into the user space of the marked element, for the
+// marker placed at the vertex of a path. The angle is the direction of the
+// path at the vertex in degrees, start is true for the first vertex of the
+// path.
+func (m *Marker) InstanceTransform(at Vertex, angle float64, start bool, strokeWidth float64) *Transform {
+	rotation := 0.0
+	if m.Orient != nil {
+		switch m.Orient.Kind {
+		case OrientAngle:
+			rotation = m.Orient.Angle
+		case OrientAuto:
+			rotation = angle
+		case OrientAutoStartReverse:
+			rotation = angle
+			if start {
+				rotation += 180
+			}
+		}
+	}
+	scale := strokeWidth
+	if m.Units == MarkerUnitsUserSpaceOnUse {
+		scale = 1
+	}
+
+	vp := ViewportTransform(m.ViewBox, m.PreserveAspectRatio, 0, 0,
+		userSize(3, m.MarkerWidth), userSize(3, m.MarkerHeight))
+	rx, ry := vp.CalcAbs(userSize(0, m.RefX), userSize(0, m.RefY))
+
+	t := Translation(at.X, at.Y)
+	t = Concatenate(t, Rotation(rotation*math.Pi/180))
+	t = Concatenate(t, Scaling(scale, scale))
+	t = Concatenate(t, Translation(-rx, -ry))
+	return Concatenate(t, vp)
+}
+
+// MarkerPosition describes a vertex of a path where a marker is placed
+type MarkerPosition struct {
+	Vertex
+	Angle float64 // direction of the path at the vertex in degrees
+}
+
+// MarkerPositions returns the vertices of the path with the directions of
+// the path at these vertices. The first position is where marker-start is
+// placed, the last position is where marker-end is placed, and the others
+// are for marker-mid. At the vertices where path segments join, the
+// direction bisects the incoming and the outgoing tangents. An arc is a
+// single segment, regardless of the number of curves that approximate it.
+func (pd *PathData) MarkerPositions() []MarkerPosition {
+	type vertex struct {
+		pt      Vertex
+		in, out *Vector
+	}
+	vv := []*vertex{}
+	cur, vi := Vertex{}, 0
+	subpath := -1 // index of the vertex that starts current subpath
+
+	// segment adds a vertex at the end of a segment with the specified
+	// tangents at its start and end
+	segment := func(to Vertex, out, in Vector) {
+		if len(vv) == 0 {
+			// a path that does not start with moveto
+			vv = append(vv, &vertex{pt: cur})
+			subpath = 0
+		}
+		if prev := vv[len(vv)-1]; prev.out == nil && out.Norm() > 0 {
+			prev.out = &out
+		}
+		v := &vertex{pt: to}
+		if in.Norm() > 0 {
+			v.in = &in
+		}
+		vv = append(vv, v)
+		cur = to
+	}
+
+	// tangent returns the first non-zero vector
+	tangent := func(dd ...Vector) Vector {
+		for _, d := range dd {
+			if d.Norm() > 0 {
+				return d
+			}
+		}
+		return Vector{}
+	}
+
+	for ci, cmd := range pd.Commands {
+		if pd.arcs[ci] && len(vv) > 0 {
+			// continues the arc, moves the end of its segment
+			c2, to := pd.Vertices[vi+1], pd.Vertices[vi+2]
+			vi += 3
+			v := vv[len(vv)-1]
+			if in := tangent(Sub(to, c2), Sub(to, cur)); in.Norm() > 0 {
+				v.in = &in
+			}
+			v.pt, cur = to, to
+			continue
+		}
+		switch cmd {
+		case PathMoveTo:
+			cur = pd.Vertices[vi]
+			vi++
+			vv = append(vv, &vertex{pt: cur})
+			subpath = len(vv) - 1
+		case PathLineTo:
+			to := pd.Vertices[vi]
+			vi++
+			d := Sub(to, cur)
+			segment(to, d, d)
+		case PathCurveTo:
+			c1, c2, to := pd.Vertices[vi], pd.Vertices[vi+1], pd.Vertices[vi+2]
+			vi += 3
+			segment(to,
+				tangent(Sub(c1, cur), Sub(c2, cur), Sub(to, cur)),
+				tangent(Sub(to, c2), Sub(to, c1), Sub(to, cur)))
+		case PathClose:
+			if subpath < 0 {
+				break
+			}
+			first := vv[subpath]
+			d := Sub(first.pt, cur)
+			segment(first.pt, d, d)
+			last := vv[len(vv)-1]
+			// the closing vertex continues into the first segment of the
+			// subpath, which in turn is entered from the closing segment
+			last.out = first.out
+			if first.in == nil {
+				first.in = last.in
+			}
+		}
+	}
+
+	direction := func(v Vector) float64 {
+		return math.Atan2(v.Y, v.X) * 180 / math.Pi
+	}
+	ret := make([]MarkerPosition, len(vv))
+	for i, v := range vv {
+		ret[i].Vertex = v.pt
+		switch {
+		case v.in != nil && v.out != nil:
+			a1, a2 := direction(*v.in), direction(*v.out)
+			da := math.Mod(a2-a1+540, 360) - 180
+			ret[i].Angle = a1 + da/2
+		case v.in != nil:
+			ret[i].Angle = direction(*v.in)
+		case v.out != nil:
+			ret[i].Angle = direction(*v.out)
+		}
+	}
+	return ret
+}
+
+// MarkerInstance describes a marker placed on a vertex of a shape
+type MarkerInstance struct {
+	Marker    *Marker
+	Transform *Transform // from the marker content into the user space of the shape
+}
+
+// MarkerInstances returns the markers placed on the vertices of a <path>,
+// <line>, <polyline> or <polygon> element, as specified with its
+// marker-start, marker-mid and marker-end properties. The properties and
+// the stroke width are taken from the element itself, a missing stroke
+// width defaults to 1.
+func (svg *Svg) MarkerInstances(it Item) ([]MarkerInstance, error) {
+	var pd *PathData
+	var shape *Shape
+	var err error
+	switch it := it.(type) {
+	case *Path:
+		shape = &it.Shape
+		pd, err = ParsePath(it.D)
+	case *Line:
+		shape = &it.Shape
+		pd = &PathData{}
+		pd.MoveTo(Vertex{userSize(0, it.X1), userSize(0, it.Y1)})
+		pd.LineTo(Vertex{userSize(0, it.X2), userSize(0, it.Y2)})
+	case *Polyline:
+		shape = &it.Shape
+		pd, err = pointsPath(it.Points, false)
+	case *Polygon:
+		shape = &it.Shape
+		pd, err = pointsPath(it.Points, true)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := svg.referenced(shape.MarkerStart).(*Marker)
+	mid, _ := svg.referenced(shape.MarkerMid).(*Marker)
+	end, _ := svg.referenced(shape.MarkerEnd).(*Marker)
+	if start == nil && mid == nil && end == nil {
+		return nil, nil
+	}
+	sw := userSize(1, shape.StrokeWidth)
+
+	ret := []MarkerInstance{}
+	pp := pd.MarkerPositions()
+	for i, p := range pp {
+		m := mid
+		if i == 0 {
+			m = start
+		} else if i == len(pp)-1 {
+			m = end
+		}
+		if m != nil {
+			ret = append(ret, MarkerInstance{
+				Marker:    m,
+				Transform: m.InstanceTransform(p.Vertex, p.Angle, i == 0, sw),
+			})
+		}
+	}
+	return ret, nil
+}
+
+// pointsPath converts the content of points attribute into path data
+func pointsPath(points string, closed bool) (*PathData, error) {
+	vv, err := ParsePoints(points)
+	if err != nil {
+		return nil, err
+	}
+	pd := &PathData{}
+	for i, v := range vv {
+		if i == 0 {
+			pd.MoveTo(v)
+		} else {
+			pd.LineTo(v)
+		}
+	}
+	if closed && len(vv) > 0 {
+		pd.Close()
+	}
+	return pd, nil
+}
@@ -0,0 +1,134 @@
+package svg
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarkerPositions(t *testing.T) {
+	tests := []struct {
+		d    string
+		want []MarkerPosition
+	}{
+		{"M0,0L10,0", []MarkerPosition{{Vertex{0, 0}, 0}, {Vertex{10, 0}, 0}}},
+		{"M0,0L10,0L10,10", []MarkerPosition{
+			{Vertex{0, 0}, 0}, {Vertex{10, 0}, 45}, {Vertex{10, 10}, 90}}},
+		{"M0,0C0,10 10,10 10,0", []MarkerPosition{{Vertex{0, 0}, 90}, {Vertex{10, 0}, -90}}},
+		{"M0,0H10V10Z", []MarkerPosition{
+			{Vertex{0, 0}, -67.5}, {Vertex{10, 0}, 45}, {Vertex{10, 10}, 157.5},
+			{Vertex{0, 0}, -67.5}}},
+		{"M0,0L10,0M20,0L20,10", []MarkerPosition{
+			{Vertex{0, 0}, 0}, {Vertex{10, 0}, 0}, {Vertex{20, 0}, 90}, {Vertex{20, 10}, 90}}},
+		// a semicircle is approximated with two curves
+		{"M0,0A5,5 0 0 1 10,0", []MarkerPosition{{Vertex{0, 0}, -90}, {Vertex{10, 0}, 90}}},
+		{"M0,0A5,5 0 0 1 10,0L10,10", []MarkerPosition{
+			{Vertex{0, 0}, -90}, {Vertex{10, 0}, 90}, {Vertex{10, 10}, 90}}},
+	}
+	for _, tt := range tests {
+		pd, err := ParsePath(tt.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := pd.MarkerPositions()
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.d, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if math.Abs(got[i].X-tt.want[i].X) > 1e-9 || math.Abs(got[i].Y-tt.want[i].Y) > 1e-9 ||
+				math.Abs(got[i].Angle-tt.want[i].Angle) > 1e-9 {
+				t.Errorf("%s: got %v, want %v", tt.d, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24">
+		<style>#styled { marker: url(#dot) }</style>
+		<defs>
+			<marker id="arrow" viewBox="0 0 10 10" refX="5" refY="5" markerWidth="6" markerHeight="6"
+				orient="auto-start-reverse">
+				<path d="M0,0L10,5L0,10z"/>
+			</marker>
+			<marker id="dot" markerUnits="userSpaceOnUse" orient="45deg" refX="1" refY="1">
+				<circle cx="1" cy="1" r="1"/>
+			</marker>
+		</defs>
+		<path id="p" d="M2,12H22" stroke-width="2" marker-start="url(#arrow)" marker-end="url(#arrow)"/>
+		<polyline id="styled" points="0,0 10,0 10,10"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arrow := doc.ByID("arrow").(*Marker)
+	if arrow.ViewBox == nil || arrow.RefX != "5" || arrow.MarkerWidth != "6" ||
+		arrow.Orient == nil || arrow.Orient.Kind != OrientAutoStartReverse || len(arrow.Items) != 1 {
+		t.Errorf("marker = %+v", arrow)
+	}
+	dot := doc.ByID("dot").(*Marker)
+	if dot.Units != MarkerUnitsUserSpaceOnUse || dot.Orient.Kind != OrientAngle || dot.Orient.Angle != 45 {
+		t.Errorf("marker = %+v", dot)
+	}
+
+	mi, err := doc.MarkerInstances(doc.ByID("p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the marker is scaled by 0.6 into its viewport, and by the stroke width
+	want := []MarkerInstance{
+		{arrow, &Transform{A: -1.2, D: -1.2, E: 2 + 6, F: 12 + 6}},
+		{arrow, &Transform{A: 1.2, D: 1.2, E: 22 - 6, F: 12 - 6}},
+	}
+	if len(mi) != len(want) {
+		t.Fatalf("got %d marker instances", len(mi))
+	}
+	for i := range mi {
+		if mi[i].Marker != want[i].Marker || !sameTransform(mi[i].Transform, want[i].Transform) {
+			t.Errorf("instance %d: got %v, want %v", i, mi[i].Transform, want[i].Transform)
+		}
+	}
+
+	mi, err = doc.MarkerInstances(doc.ByID("styled"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mi) != 3 {
+		t.Fatalf("got %d marker instances", len(mi))
+	}
+	for i, v := range []Vertex{{0, 0}, {10, 0}, {10, 10}} {
+		x, y := mi[i].Transform.CalcAbs(1, 1)
+		if mi[i].Marker != dot || math.Abs(x-v.X) > 1e-9 || math.Abs(y-v.Y) > 1e-9 {
+			t.Errorf("instance %d: reference point at %v,%v, want %v", i, x, y, v)
+		}
+	}
+
+	if mi, err := doc.MarkerInstances(doc.ByID("arrow")); mi != nil || err != nil {
+		t.Errorf("got %v, %v for a marker element", mi, err)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+
+	for _, in := range []string{
+		`<svg><marker orient="sideways"/></svg>`,
+		`<svg><marker markerUnits="px"/></svg>`,
+		`<svg><path marker-mid="#dot"/></svg>`,
+	} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+	if _, err := Parse(`<svg><marker markerUnits="px"/></svg>`); err == nil ||
+		!strings.Contains(err.Error(), "invalid markerUnits") {
+		t.Errorf("markerUnits error = %v", err)
+	}
+}
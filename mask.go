@@ -0,0 +1,169 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaskType implements SVG mask-type property value
+type MaskType int
+
+const (
+	MaskTypeLuminance = MaskType(iota)
+	MaskTypeAlpha
+)
+
+func (mt MaskType) String() string {
+	switch mt {
+	case MaskTypeLuminance:
+		return "luminance"
+	case MaskTypeAlpha:
+		return "alpha"
+	default:
+		return ""
+	}
+}
+
+func (mt *MaskType) UnmarshalText(text []byte) error {
+	s := string(text)
+	switch s {
+	case "luminance":
+		*mt = MaskTypeLuminance
+	case "alpha":
+		*mt = MaskTypeAlpha
+	default:
+		return errors.New("invalid mask-type value")
+	}
+	return nil
+}
+
+// Mask implements SVG <mask> element
+//
+// The mask content is rendered into an offscreen image, whose luminance or
+// alpha channel, as selected with mask-type, is applied to elements that
+// refer to the mask with the mask property, see Svg.MaskOf.
+type Mask struct {
+	Node
+	Presentation
+	Units        GradientUnits // maskUnits, objectBoundingBox when unspecified
+	ContentUnits GradientUnits // maskContentUnits, userSpaceOnUse when unspecified
+	X            Coordinate
+	Y            Coordinate
+	Width        Length
+	Height       Length
+	Type         *MaskType // mask-type, luminance if not specified
+}
+
+var maskProperties = []string{
+	"mask-type",
+}
+
+func (m *Mask) set(name, v string) (known bool, err error) {
+	known = true
+	switch name {
+	case "mask-type":
+		r := MaskTypeLuminance
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			m.Type = &r
+		}
+	default:
+		known = false
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return
+}
+
+func (m *Mask) read(src sourcer) (err error) {
+	err = m.item.read(src)
+	if err != nil {
+		return
+	}
+	err = m.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	if v, exists := src.Attr("maskUnits"); exists {
+		if err = m.Units.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid maskUnits: %w", err)
+		}
+	}
+	if v, exists := src.Attr("maskContentUnits"); exists {
+		if err = m.ContentUnits.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid maskContentUnits: %w", err)
+		}
+	}
+	if s, ok := src.Attr("x"); ok {
+		m.X = Coordinate(s)
+	}
+	if s, ok := src.Attr("y"); ok {
+		m.Y = Coordinate(s)
+	}
+	if s, ok := src.Attr("width"); ok {
+		m.Width = Length(s)
+	}
+	if s, ok := src.Attr("height"); ok {
+		m.Height = Length(s)
+	}
+	err = readProperties(src, maskProperties, m.set)
+	if err != nil {
+		return
+	}
+	return readChildren(src, &m.Items)
+}
+
+func (m *Mask) write(tgt targeter) {
+	m.item.write(tgt)
+	m.Presentation.write(tgt)
+	if m.Type != nil {
+		tgt.Property("mask-type", m.Type.String())
+	}
+	tgt.Attr("maskUnits", m.Units.String())
+	tgt.Attr("maskContentUnits", m.ContentUnits.String())
+	tgt.Attr("x", string(m.X))
+	tgt.Attr("y", string(m.Y))
+	tgt.Attr("width", string(m.Width))
+	tgt.Attr("height", string(m.Height))
+	m.writeItems(tgt)
+}
+
+// EffectiveType returns the mask type, which defaults to luminance
+func (m *Mask) EffectiveType() MaskType {
+	if m.Type == nil {
+		return MaskTypeLuminance
+	}
+	return *m.Type
+}
+
+// Region returns the effective mask region, the attributes that are not
+// specified default to -10%, -10%, 120% and 120% respectively. The values
+// are interpreted according to Units.
+func (m *Mask) Region() (x, y Coordinate, width, height Length) {
+	x, y, width, height = m.X, m.Y, m.Width, m.Height
+	if x == "" {
+		x = "-10%"
+	}
+	if y == "" {
+		y = "-10%"
+	}
+	if width == "" {
+		width = "120%"
+	}
+	if height == "" {
+		height = "120%"
+	}
+	return
+}
+
+// MaskOf returns the <mask> element referenced by the mask property of it,
+// or nil if the property is not specified or the reference can not be
+// resolved
+func (svg *Svg) MaskOf(it Item) *Mask {
+	p, ok := it.(presenter)
+	if !ok {
+		return nil
+	}
+	m, _ := svg.referenced(p.presentation().Mask).(*Mask)
+	return m
+}
@@ -0,0 +1,72 @@
+package svg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24">
+		<defs>
+			<mask id="fade" maskUnits="userSpaceOnUse" maskContentUnits="objectBoundingBox"
+				x="0" y="0" width="24" height="24" style="mask-type:alpha" fill="#fff">
+				<rect width="1" height="1" fill-opacity="0.5"/>
+			</mask>
+			<mask id="plain"><circle r="5"/></mask>
+		</defs>
+		<g id="g" mask="url(#fade)"><rect id="r1" width="24" height="24"/></g>
+		<path id="p" d="M0,0H24" mask="url(#plain)" clip-path="url(#nothing)"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fade := doc.ByID("fade").(*Mask)
+	if fade.Units != GradientUnitsUserSpaceOnUse || fade.ContentUnits != GradientUnitsObjectBoundingBox ||
+		fade.EffectiveType() != MaskTypeAlpha || fade.Fill == nil || len(fade.Items) != 1 {
+		t.Errorf("mask = %+v", fade)
+	}
+	if x, y, w, h := fade.Region(); x != "0" || y != "0" || w != "24" || h != "24" {
+		t.Errorf("region = %s %s %s %s", x, y, w, h)
+	}
+
+	plain := doc.ByID("plain").(*Mask)
+	if plain.EffectiveType() != MaskTypeLuminance {
+		t.Errorf("default mask-type = %v", plain.EffectiveType())
+	}
+	if x, y, w, h := plain.Region(); x != "-10%" || y != "-10%" || w != "120%" || h != "120%" {
+		t.Errorf("default region = %s %s %s %s", x, y, w, h)
+	}
+
+	if got := doc.MaskOf(doc.ByID("g")); got != fade {
+		t.Errorf("MaskOf(g) = %v", got)
+	}
+	if got := doc.MaskOf(doc.ByID("p")); got != plain {
+		t.Errorf("MaskOf(p) = %v", got)
+	}
+	if got := doc.MaskOf(doc.ByID("r1")); got != nil {
+		t.Errorf("MaskOf(r1) = %v", got)
+	}
+
+	for _, opts := range [][]WriteOption{nil, {WithPropertyFormat(PropertiesAsStyle)}} {
+		doc, doc2, out := roundTrip(t, data, opts...)
+		if opts != nil {
+			clearPropertySources(doc)
+			clearPropertySources(doc2)
+		}
+		if !reflect.DeepEqual(doc, doc2) {
+			t.Errorf("round trip mismatch, written document:\n%s", out)
+		}
+	}
+
+	for _, in := range []string{
+		`<svg><mask mask-type="color"/></svg>`,
+		`<svg><mask maskUnits="bbox"/></svg>`,
+		`<svg><rect mask="#fade"/></svg>`,
+	} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
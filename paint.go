@@ -2,6 +2,7 @@ package svg
 
 import (
 	"errors"
+	"fmt"
 )
 
 type RGB struct {
@@ -10,20 +11,101 @@ type RGB struct {
 	B uint8
 }
 
+func (c RGB) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
 type PaintKind int
 
 const (
 	PaintKindNone = PaintKind(iota)
 	PaintKindRGB
 	PaintKindGradient
+	PaintKindCurrentColor
+	PaintKindURL // reference to a paint server that is not resolved or missing
+	PaintKindPattern
 )
 
+// PaintServer is implemented by elements that can be referenced as paint
+// with url(#id) syntax
+type PaintServer interface {
+	Item
+	paintServer()
+}
+
 type Paint struct {
-	Kind     PaintKind
-	Color    RGB
+	Kind  PaintKind
+	Color RGB
+	Alpha *float64 // alpha channel of the color, opaque if nil
+
+	// Ref holds the id of the paint server referenced with url(#id), and
+	// Fallback holds the optional paint that follows the reference. Server
+	// is bound to the referenced element by Svg.Resolve, Gradient is bound
+	// to the common part of the referenced gradient.
+	Ref      string
+	Fallback *Paint
+	Server   PaintServer
 	Gradient *Gradient
 }
 
+// String formats the paint in SVG <paint> syntax
+func (p *Paint) String() string {
+	if len(p.Ref) > 0 {
+		s := "url(#" + p.Ref + ")"
+		if p.Fallback != nil {
+			s += " " + p.Fallback.String()
+		}
+		return s
+	}
+	switch p.Kind {
+	case PaintKindNone:
+		return "none"
+	case PaintKindRGB:
+		if p.Alpha != nil {
+			return fmt.Sprintf("rgba(%d,%d,%d,%s)", p.Color.R, p.Color.G, p.Color.B, formatNumber(*p.Alpha))
+		}
+		return p.Color.String()
+	case PaintKindCurrentColor:
+		return "currentColor"
+	default:
+		return ""
+	}
+}
+
+// Effective returns the paint that is used for rendering. For references
+// that could not be resolved, this is the fallback paint if specified, or
+// none otherwise.
+func (p *Paint) Effective() *Paint {
+	if p.Kind != PaintKindURL {
+		return p
+	}
+	if p.Fallback != nil {
+		return p.Fallback
+	}
+	return &Paint{Kind: PaintKindNone}
+}
+
+// bind resolves the paint server reference against the document
+func (p *Paint) bind(doc *Svg) {
+	if len(p.Ref) == 0 {
+		return
+	}
+	p.Server, _ = doc.ByID(p.Ref).(PaintServer)
+	p.Gradient = nil
+	switch s := p.Server.(type) {
+	case *LinearGradient:
+		p.Kind = PaintKindGradient
+		p.Gradient = &s.Gradient
+	case *RadialGradient:
+		p.Kind = PaintKindGradient
+		p.Gradient = &s.Gradient
+	case *Pattern:
+		p.Kind = PaintKindPattern
+	default:
+		p.Kind = PaintKindURL
+	}
+}
+
 type GradientUnits int
 
 const (
@@ -43,14 +125,14 @@ func (gu GradientUnits) String() string {
 	}
 }
 
-func (gu GradientUnits) Unmarshal(s string) error {
+func (gu *GradientUnits) Unmarshal(s string) error {
 	switch s {
 	case "userSpaceOnUse":
-		gu = GradientUnitsUserSpaceOnUse
+		*gu = GradientUnitsUserSpaceOnUse
 	case "objectBoundingBox":
-		gu = GradientUnitsObjectBoundingBox
+		*gu = GradientUnitsObjectBoundingBox
 	default:
-		return errors.New("invalid gradient-units value")
+		return errors.New("invalid units value")
 	}
 	return nil
 }
@@ -77,35 +159,177 @@ func (sm SpreadMethod) String() string {
 	}
 }
 
-func (sm SpreadMethod) Unmarshal(s string) error {
+func (sm *SpreadMethod) Unmarshal(s string) error {
 	switch s {
 	case "pad":
-		sm = SpreadMethodPad
+		*sm = SpreadMethodPad
 	case "reflect":
-		sm = SpreadMethodReflect
-	case "round":
-		sm = SpreadMethodRepeat
+		*sm = SpreadMethodReflect
+	case "repeat":
+		*sm = SpreadMethodRepeat
 	case "":
-		sm = SpreadMethodUnspecified
+		*sm = SpreadMethodUnspecified
 	default:
 		return errors.New("invalid spread-method value")
 	}
 	return nil
 }
 
+// Gradient holds attributes and stops that are common to SVG
+// <linearGradient> and <radialGradient> elements
+//
+// Attributes and stops that are not specified are inherited from the
+// template gradient referenced with Href, use Resolve methods of the concrete
+// gradient types to obtain the effective values.
 type Gradient struct {
+	item
+	Href              string
 	Units             GradientUnits
-	Stops             []GradientStop
-	GradientTransform Transform
+	Stops             []*GradientStop
+	GradientTransform *Transform
 	SpreadMethod      SpreadMethod
+
+	// extraChildren keeps the children other than stops, such as <title> and
+	// <animate>
+	extraChildren
+}
+
+func (g *Gradient) paintServer() {}
+
+func (g *Gradient) children() []Item {
+	ret := make([]Item, 0, len(g.Stops)+len(g.Items))
+	for _, s := range g.Stops {
+		ret = append(ret, s)
+	}
+	return append(ret, g.Items...)
 }
 
+func (g *Gradient) read(src sourcer) (err error) {
+	err = g.item.read(src)
+	if err != nil {
+		return
+	}
+	g.Href = g.readHref(src)
+	if v, exists := src.Attr("gradientUnits"); exists {
+		if err = g.Units.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid gradientUnits: %w", err)
+		}
+	}
+	if v, exists := src.Attr("gradientTransform"); exists {
+		g.GradientTransform, err = parseTransformAttr(v)
+		if err != nil {
+			return fmt.Errorf("invalid gradientTransform: %w", err)
+		}
+	}
+	if v, exists := src.Attr("spreadMethod"); exists {
+		if err = g.SpreadMethod.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid spreadMethod: %w", err)
+		}
+	}
+	return src.ForEachContent(func(tag string, cs sourcer) error {
+		if tag != "stop" {
+			return g.readExtra(tag, cs, len(g.Stops))
+		}
+		stop := &GradientStop{}
+		if err := readItem(stop, cs); err != nil {
+			return err
+		}
+		g.Stops = append(g.Stops, stop)
+		return nil
+	}, nil, func(text string) error {
+		return g.readComment(text, len(g.Stops))
+	})
+}
+
+func (g *Gradient) write(tgt targeter) {
+	g.item.write(tgt)
+	if len(g.Href) > 0 {
+		g.writeHref(tgt, g.Href)
+	}
+	tgt.Attr("gradientUnits", g.Units.String())
+	if g.GradientTransform != nil {
+		tgt.Attr("gradientTransform", g.GradientTransform.String())
+	}
+	tgt.Attr("spreadMethod", g.SpreadMethod.String())
+}
+
+func (g *Gradient) writeStops(tgt targeter) {
+	g.writeAround(tgt, len(g.Stops), func(i int) {
+		tgt.Child("stop", g.Stops[i].write)
+	})
+}
+
+// inherit assigns attributes that are not specified in g from template t
+func (g *Gradient) inherit(t *Gradient) {
+	if g.Units == GradientUnitsUnspecified {
+		g.Units = t.Units
+	}
+	if g.GradientTransform == nil {
+		g.GradientTransform = t.GradientTransform
+	}
+	if g.SpreadMethod == SpreadMethodUnspecified {
+		g.SpreadMethod = t.SpreadMethod
+	}
+	if len(g.Stops) == 0 {
+		g.Stops = t.Stops
+	}
+}
+
+// GradientStop implements SVG <stop> element
 type GradientStop struct {
+	item
 	Offset  float64
-	Color   RGB
-	Opacity float64
+	Color   *Paint   // stop-color, black if not specified
+	Opacity *float64 // stop-opacity, 1 if not specified
+}
+
+var stopProperties = []string{
+	"stop-color",
+	"stop-opacity",
+}
+
+func (s *GradientStop) set(name, v string) (known bool, err error) {
+	known = true
+	switch name {
+	case "stop-color":
+		s.Color, err = parseColorPaint(v)
+	case "stop-opacity":
+		s.Opacity, err = ParseOpacity(v)
+	default:
+		known = false
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return
 }
 
+func (s *GradientStop) read(src sourcer) (err error) {
+	err = s.item.read(src)
+	if err != nil {
+		return
+	}
+	if v, exists := src.Attr("offset"); exists {
+		s.Offset, err = parseOffset(v)
+		if err != nil {
+			return fmt.Errorf("invalid offset: %w", err)
+		}
+	}
+	return readProperties(src, stopProperties, s.set)
+}
+
+func (s *GradientStop) write(tgt targeter) {
+	s.item.write(tgt)
+	tgt.Attr("offset", formatNumber(s.Offset))
+	if s.Color != nil {
+		tgt.Property("stop-color", s.Color.String())
+	}
+	if s.Opacity != nil {
+		tgt.Property("stop-opacity", formatNumber(*s.Opacity))
+	}
+}
+
+// LinearGradient implements SVG <linearGradient> element
 type LinearGradient struct {
 	Gradient
 	X1 Coordinate
@@ -114,6 +338,63 @@ type LinearGradient struct {
 	Y2 Coordinate
 }
 
+func (lg *LinearGradient) read(src sourcer) (err error) {
+	err = lg.Gradient.read(src)
+	if err != nil {
+		return
+	}
+	if s, ok := src.Attr("x1"); ok {
+		lg.X1 = Coordinate(s)
+	}
+	if s, ok := src.Attr("y1"); ok {
+		lg.Y1 = Coordinate(s)
+	}
+	if s, ok := src.Attr("x2"); ok {
+		lg.X2 = Coordinate(s)
+	}
+	if s, ok := src.Attr("y2"); ok {
+		lg.Y2 = Coordinate(s)
+	}
+	return
+}
+
+func (lg *LinearGradient) write(tgt targeter) {
+	lg.Gradient.write(tgt)
+	tgt.Attr("x1", string(lg.X1))
+	tgt.Attr("y1", string(lg.Y1))
+	tgt.Attr("x2", string(lg.X2))
+	tgt.Attr("y2", string(lg.Y2))
+	lg.writeStops(tgt)
+}
+
+// Resolve returns a copy of the gradient, in which attributes and stops that
+// are not specified are inherited from the chain of href templates
+func (lg *LinearGradient) Resolve(doc *Svg) *LinearGradient {
+	ret := *lg
+	for _, t := range doc.gradientTemplates(&lg.Gradient) {
+		switch t := t.(type) {
+		case *LinearGradient:
+			ret.Gradient.inherit(&t.Gradient)
+			if ret.X1 == "" {
+				ret.X1 = t.X1
+			}
+			if ret.Y1 == "" {
+				ret.Y1 = t.Y1
+			}
+			if ret.X2 == "" {
+				ret.X2 = t.X2
+			}
+			if ret.Y2 == "" {
+				ret.Y2 = t.Y2
+			}
+		case *RadialGradient:
+			ret.Gradient.inherit(&t.Gradient)
+		}
+	}
+	return &ret
+}
+
+// RadialGradient implements SVG <radialGradient> element
 type RadialGradient struct {
 	Gradient
 	Cx     Coordinate
@@ -121,6 +402,101 @@ type RadialGradient struct {
 	Radius Length
 	Fx     Coordinate
 	Fy     Coordinate
+	Fr     Length
+}
+
+func (rg *RadialGradient) read(src sourcer) (err error) {
+	err = rg.Gradient.read(src)
+	if err != nil {
+		return
+	}
+	if s, ok := src.Attr("cx"); ok {
+		rg.Cx = Coordinate(s)
+	}
+	if s, ok := src.Attr("cy"); ok {
+		rg.Cy = Coordinate(s)
+	}
+	if s, ok := src.Attr("r"); ok {
+		rg.Radius = Length(s)
+	}
+	if s, ok := src.Attr("fx"); ok {
+		rg.Fx = Coordinate(s)
+	}
+	if s, ok := src.Attr("fy"); ok {
+		rg.Fy = Coordinate(s)
+	}
+	if s, ok := src.Attr("fr"); ok {
+		rg.Fr = Length(s)
+	}
+	return
+}
+
+func (rg *RadialGradient) write(tgt targeter) {
+	rg.Gradient.write(tgt)
+	tgt.Attr("cx", string(rg.Cx))
+	tgt.Attr("cy", string(rg.Cy))
+	tgt.Attr("r", string(rg.Radius))
+	tgt.Attr("fx", string(rg.Fx))
+	tgt.Attr("fy", string(rg.Fy))
+	tgt.Attr("fr", string(rg.Fr))
+	rg.writeStops(tgt)
+}
+
+// Resolve returns a copy of the gradient, in which attributes and stops that
+// are not specified are inherited from the chain of href templates
+func (rg *RadialGradient) Resolve(doc *Svg) *RadialGradient {
+	ret := *rg
+	for _, t := range doc.gradientTemplates(&rg.Gradient) {
+		switch t := t.(type) {
+		case *RadialGradient:
+			ret.Gradient.inherit(&t.Gradient)
+			if ret.Cx == "" {
+				ret.Cx = t.Cx
+			}
+			if ret.Cy == "" {
+				ret.Cy = t.Cy
+			}
+			if ret.Radius == "" {
+				ret.Radius = t.Radius
+			}
+			if ret.Fx == "" {
+				ret.Fx = t.Fx
+			}
+			if ret.Fy == "" {
+				ret.Fy = t.Fy
+			}
+			if ret.Fr == "" {
+				ret.Fr = t.Fr
+			}
+		case *LinearGradient:
+			ret.Gradient.inherit(&t.Gradient)
+		}
+	}
+	return &ret
+}
+
+// gradientTemplates returns the chain of gradients referenced with href,
+// starting with the immediate template of g. The chain ends at the first
+// missing, non-gradient or already visited reference.
+func (svg *Svg) gradientTemplates(g *Gradient) []Item {
+	ret := []Item{}
+	visited := map[*Gradient]bool{g: true}
+	for {
+		var t *Gradient
+		it := svg.ByID(hrefID(g.Href))
+		switch it := it.(type) {
+		case *LinearGradient:
+			t = &it.Gradient
+		case *RadialGradient:
+			t = &it.Gradient
+		}
+		if t == nil || visited[t] {
+			return ret
+		}
+		visited[t] = true
+		ret = append(ret, it)
+		g = t
+	}
 }
 
 // FillRule implements SVG <fill-rule> type
@@ -134,6 +510,8 @@ const (
 
 func (fr FillRule) String() string {
 	switch fr {
+	case FillRuleInherit:
+		return "inherit"
 	case FillRuleNonZero:
 		return "nonzero"
 	case FillRuleEvenOdd:
@@ -143,15 +521,15 @@ func (fr FillRule) String() string {
 	}
 }
 
-func (fr FillRule) UnmarshalText(text []byte) error {
+func (fr *FillRule) UnmarshalText(text []byte) error {
 	s := string(text)
 	switch s {
 	case "nonzero":
-		fr = FillRuleNonZero
+		*fr = FillRuleNonZero
 	case "evenodd":
-		fr = FillRuleEvenOdd
+		*fr = FillRuleEvenOdd
 	case "inherit":
-		fr = FillRuleInherit
+		*fr = FillRuleInherit
 	default:
 		return errors.New("invalid fill-rule value")
 	}
@@ -182,17 +560,17 @@ func (lc LineCap) String() string {
 	}
 }
 
-func (lc LineCap) UnmarshalText(text []byte) error {
+func (lc *LineCap) UnmarshalText(text []byte) error {
 	s := string(text)
 	switch s {
 	case "inherit":
-		lc = LineCapInherit
+		*lc = LineCapInherit
 	case "butt":
-		lc = LineCapButt
+		*lc = LineCapButt
 	case "round":
-		lc = LineCapRound
+		*lc = LineCapRound
 	case "square":
-		lc = LineCapSquare
+		*lc = LineCapSquare
 	default:
 		return errors.New("invalid stroke-linecap value")
 	}
@@ -208,19 +586,49 @@ const (
 	LineJoinBevel
 )
 
-func (lj LineJoin) UnmarshalText(text []byte) error {
+func (lj LineJoin) String() string {
+	switch lj {
+	case LineJoinInerit:
+		return "inherit"
+	case LineJoinMiter:
+		return "miter"
+	case LineJoinRound:
+		return "round"
+	case LineJoinBevel:
+		return "bevel"
+	default:
+		return ""
+	}
+}
+
+func (lj *LineJoin) UnmarshalText(text []byte) error {
 	s := string(text)
 	switch s {
 	case "inherit":
-		lj = LineJoinInerit
+		*lj = LineJoinInerit
 	case "miter":
-		lj = LineJoinMiter
+		*lj = LineJoinMiter
 	case "round":
-		lj = LineJoinRound
+		*lj = LineJoinRound
 	case "bevel":
-		lj = LineJoinBevel
+		*lj = LineJoinBevel
 	default:
 		return errors.New("invalid stroke-linejoin value")
 	}
 	return nil
 }
+
+// DashArray implements SVG stroke-dasharray property value, an empty (non-nil)
+// array corresponds to 'none'
+type DashArray []Length
+
+func (da DashArray) String() string {
+	if len(da) == 0 {
+		return "none"
+	}
+	s := string(da[0])
+	for _, l := range da[1:] {
+		s += "," + string(l)
+	}
+	return s
+}
@@ -0,0 +1,249 @@
+package svg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGradients(t *testing.T) {
+	data := `<svg xmlns:xlink="http://www.w3.org/1999/xlink">
+		<defs>
+			<style>#s2 { stop-opacity: 0.5 }</style>
+			<linearGradient id="base" x1="0" x2="1" gradientUnits="userSpaceOnUse" spreadMethod="reflect">
+				<stop offset="0" stop-color="#f00"/>
+				<stop id="s2" offset="50%" style="stop-color:#0f0"/>
+				<stop offset="1.5" stop-color="#00f" stop-opacity="0.25"/>
+			</linearGradient>
+			<linearGradient id="glossy" xlink:href="#base" x2="0.5" gradientTransform="rotate(90)"/>
+			<radialGradient id="radial" href="#glossy" cx="0.5" r="0.5"/>
+			<radialGradient id="loop1" href="#loop2"><stop offset="0"/></radialGradient>
+			<radialGradient id="loop2" href="#loop1" fx="1"/>
+		</defs>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, ok := doc.ByID("base").(*LinearGradient)
+	if !ok {
+		t.Fatal("missing base gradient")
+	}
+	if base.Units != GradientUnitsUserSpaceOnUse || base.SpreadMethod != SpreadMethodReflect {
+		t.Errorf("units = %v, spread = %v", base.Units, base.SpreadMethod)
+	}
+	if len(base.Stops) != 3 {
+		t.Fatalf("stops = %d", len(base.Stops))
+	}
+	s := base.Stops[1]
+	if s.Offset != 0.5 || s.Color == nil || s.Color.Color != (RGB{0, 0xff, 0}) ||
+		s.Opacity == nil || *s.Opacity != 0.5 {
+		t.Errorf("stop = %+v", s)
+	}
+	if base.Stops[2].Offset != 1 {
+		t.Errorf("offset is not clamped: %g", base.Stops[2].Offset)
+	}
+	if doc.ByID("s2") != s {
+		t.Errorf("stops are not indexed")
+	}
+
+	glossy := doc.ByID("glossy").(*LinearGradient)
+	if glossy.Href != "#base" || len(glossy.Stops) != 0 {
+		t.Errorf("href = %q, stops = %d", glossy.Href, len(glossy.Stops))
+	}
+	rl := glossy.Resolve(doc)
+	if rl.X1 != "0" || rl.X2 != "0.5" || len(rl.Stops) != 3 ||
+		rl.Units != GradientUnitsUserSpaceOnUse || rl.GradientTransform == nil {
+		t.Errorf("resolved = %+v", rl)
+	}
+	if len(glossy.Stops) != 0 || glossy.X1 != "" {
+		t.Errorf("Resolve should not modify the original")
+	}
+
+	rr := doc.ByID("radial").(*RadialGradient).Resolve(doc)
+	if rr.Cx != "0.5" || len(rr.Stops) != 3 || rr.SpreadMethod != SpreadMethodReflect ||
+		rr.GradientTransform == nil {
+		t.Errorf("resolved = %+v", rr)
+	}
+
+	loop := doc.ByID("loop2").(*RadialGradient).Resolve(doc)
+	if loop.Fx != "1" || len(loop.Stops) != 1 {
+		t.Errorf("resolved = %+v", loop)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+	for _, want := range []string{`id="glossy" xlink:href="#base"`, `id="radial" href="#glossy"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("written document does not contain %s:\n%s", want, out)
+		}
+	}
+	if _, err := Parse(`<svg><linearGradient spreadMethod="mirror"/></svg>`); err == nil ||
+		!strings.Contains(err.Error(), "invalid spreadMethod") {
+		t.Errorf("spreadMethod error = %v", err)
+	}
+
+	doc, err = Parse(`<svg><linearGradient><stop offset="0" stop-color="CurrentColor"/></linearGradient></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := doc.Items[0].(*LinearGradient).Stops[0].Color; c == nil || c.Kind != PaintKindCurrentColor {
+		t.Errorf("stop-color = %v", c)
+	}
+	for _, v := range []string{"none", "url(#g)", "url(#g) red"} {
+		in := `<svg><linearGradient><stop offset="0" stop-color="` + v + `"/></linearGradient></svg>`
+		if _, err := Parse(in); err == nil || !strings.Contains(err.Error(), "invalid stop-color") {
+			t.Errorf("stop-color %s: error = %v", v, err)
+		}
+	}
+}
+
+func TestParsePaint(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	tests := []struct {
+		in    string
+		kind  PaintKind
+		color RGB
+		alpha *float64
+	}{
+		{"none", PaintKindNone, RGB{}, nil},
+		{"currentColor", PaintKindCurrentColor, RGB{}, nil},
+		{"#abc", PaintKindRGB, RGB{0xaa, 0xbb, 0xcc}, nil},
+		{"#abc8", PaintKindRGB, RGB{0xaa, 0xbb, 0xcc}, f(0x88 / 255.0)},
+		{"#E30613", PaintKindRGB, RGB{0xe3, 0x06, 0x13}, nil},
+		{"#e3061380", PaintKindRGB, RGB{0xe3, 0x06, 0x13}, f(0x80 / 255.0)},
+		{"blue", PaintKindRGB, RGB{0, 0, 0xff}, nil},
+		{"White", PaintKindRGB, RGB{0xff, 0xff, 0xff}, nil},
+		{"grey", PaintKindRGB, RGB{0x80, 0x80, 0x80}, nil},
+		{"transparent", PaintKindRGB, RGB{}, f(0)},
+		{"rgb(255, 128, 0)", PaintKindRGB, RGB{0xff, 0x80, 0}, nil},
+		{"rgb(100%, 50%, 0%)", PaintKindRGB, RGB{0xff, 0x80, 0}, nil},
+		{"rgba(300, -1, 0, 0.5)", PaintKindRGB, RGB{0xff, 0, 0}, f(0.5)},
+		{"rgb(0 0 255 / 25%)", PaintKindRGB, RGB{0, 0, 0xff}, f(0.25)},
+		{"hsl(120, 100%, 50%)", PaintKindRGB, RGB{0, 0xff, 0}, nil},
+		{"hsla(240deg, 100%, 25%, 1)", PaintKindRGB, RGB{0, 0, 0x80}, f(1)},
+		{"hsl(0.5turn 100% 50%)", PaintKindRGB, RGB{0, 0xff, 0xff}, nil},
+		{"HSL(0, 0%, 100%)", PaintKindRGB, RGB{0xff, 0xff, 0xff}, nil},
+	}
+	for _, tt := range tests {
+		p, err := ParsePaint(tt.in)
+		if err != nil {
+			t.Errorf("ParsePaint(%q): %s", tt.in, err)
+			continue
+		}
+		if p.Kind != tt.kind || p.Color != tt.color ||
+			(p.Alpha == nil) != (tt.alpha == nil) ||
+			(p.Alpha != nil && *p.Alpha != *tt.alpha) {
+			t.Errorf("ParsePaint(%q) = %+v", tt.in, p)
+		}
+	}
+
+	for _, in := range []string{"", "bleu", "#ab", "#abcde", "#ggg", "rgb(1,2)", "rgb(1 2 3 /)", "foo(1,2,3)", "rgb(1,2,3"} {
+		if p, err := ParsePaint(in); err == nil {
+			t.Errorf("ParsePaint(%q) = %+v, expected an error", in, p)
+		}
+	}
+}
+
+func TestPaintString(t *testing.T) {
+	for in, want := range map[string]string{
+		"none":              "none",
+		"currentcolor":      "currentColor",
+		"white":             "#ffffff",
+		"rgba(1,2,3,50%)":   "rgba(1,2,3,0.5)",
+		"hsl(0 100% 50%)":   "#ff0000",
+		"transparent":       "rgba(0,0,0,0)",
+		"rgb(1 2 3 / 0.25)": "rgba(1,2,3,0.25)",
+	} {
+		p, err := ParsePaint(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.String(); got != want {
+			t.Errorf("ParsePaint(%q).String() = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPaintReferences(t *testing.T) {
+	data := `<svg>
+		<defs>
+			<linearGradient id="g1"><stop offset="0" stop-color="red"/></linearGradient>
+			<radialGradient id="g2"><stop offset="0" stop-color="blue"/></radialGradient>
+		</defs>
+		<rect id="r1" fill="url(#g1)" stroke="url('#g2') red"/>
+		<rect id="r2" fill="url(#missing) #00f" stroke="url(#missing)"/>
+		<rect id="r3" fill="url(#r1) none"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1 := doc.ByID("r1").(*Rect)
+	if r1.Fill.Kind != PaintKindGradient || r1.Fill.Server != doc.ByID("g1") {
+		t.Errorf("fill = %+v", r1.Fill)
+	}
+	if r1.Stroke.Kind != PaintKindGradient || r1.Stroke.Server != doc.ByID("g2") ||
+		r1.Stroke.Fallback == nil || r1.Stroke.Fallback.Color != (RGB{0xff, 0, 0}) {
+		t.Errorf("stroke = %+v", r1.Stroke)
+	}
+	if r1.Fill.Gradient != &doc.ByID("g1").(*LinearGradient).Gradient ||
+		r1.Stroke.Gradient != &doc.ByID("g2").(*RadialGradient).Gradient {
+		t.Errorf("gradients are not bound: %p, %p", r1.Fill.Gradient, r1.Stroke.Gradient)
+	}
+	if r1.Fill.Effective() != r1.Fill {
+		t.Errorf("resolved paint should be effective")
+	}
+
+	r2 := doc.ByID("r2").(*Rect)
+	if r2.Fill.Kind != PaintKindURL || r2.Fill.Server != nil {
+		t.Errorf("fill = %+v", r2.Fill)
+	}
+	if e := r2.Fill.Effective(); e.Kind != PaintKindRGB || e.Color != (RGB{0, 0, 0xff}) {
+		t.Errorf("missing reference should fall back: %+v", e)
+	}
+	if e := r2.Stroke.Effective(); e.Kind != PaintKindNone {
+		t.Errorf("missing reference without fallback should be none: %+v", e)
+	}
+
+	r3 := doc.ByID("r3").(*Rect)
+	if r3.Fill.Kind != PaintKindURL || r3.Fill.Effective().Kind != PaintKindNone {
+		t.Errorf("reference to a non-paint server should fall back: %+v", r3.Fill)
+	}
+
+	for in, want := range map[string]string{
+		"url(#a)":              "url(#a)",
+		`url( "#a" ) #ff0000`:  "url(#a) #ff0000",
+		"url(#a) currentColor": "url(#a) currentColor",
+		"URL(#a)":              "url(#a)",
+	} {
+		p, err := ParsePaint(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.String(); got != want {
+			t.Errorf("ParsePaint(%q).String() = %q, want %q", in, got, want)
+		}
+	}
+	for _, in := range []string{"url(a.svg#a)", "url(#a", "url()", "url(#a) url(#b)", "url(#a) bleu"} {
+		if _, err := ParsePaint(in); err == nil {
+			t.Errorf("ParsePaint(%q): expected an error", in)
+		}
+	}
+
+	if _, _, err := ParseColor("url(#a)"); err == nil || !strings.Contains(err.Error(), "url()") {
+		t.Errorf("ParseColor(url(#a)) = %v", err)
+	}
+	if _, err := ParsePaint("url(a.svg#a)"); err == nil || !strings.Contains(err.Error(), "url()") {
+		t.Errorf("ParsePaint(url(a.svg#a)) = %v", err)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+}
@@ -2,54 +2,362 @@ package svg
 
 import (
 	"errors"
+	"strings"
 
 	xg "github.com/adnsv/xmlgo"
 )
 
-func Parse(in string) (*Svg, error) {
+// ParseMode selects how invalid attribute values are handled
+type ParseMode int
+
+const (
+	// ParseStrict fails on the first invalid attribute value, invalid CSS
+	// declarations are ignored in either mode
+	ParseStrict = ParseMode(iota)
+
+	// ParseLenient drops invalid values and reports them as warnings
+	ParseLenient
+)
+
+type parseOptions struct {
+	mode ParseMode
+	warn func(w *ParseError)
+}
+
+// ParseOption customizes the behavior of Parse
+type ParseOption func(*parseOptions)
+
+// WithParseMode selects how invalid attribute values are handled, the
+// default is ParseStrict
+func WithParseMode(m ParseMode) ParseOption {
+	return func(o *parseOptions) {
+		o.mode = m
+	}
+}
+
+// WithWarnings installs a collector that receives the elements and the
+// attributes dropped by the parser, in both modes. These include the values
+// that are skipped in ParseLenient mode, and the elements within the text of
+// <title>, <desc> and <style>. Path data that can not be parsed is kept as
+// is, and reported as well. Unsupported elements and attributes are not
+// dropped, these are kept for writing, along with the comments.
+func WithWarnings(fn func(w *ParseError)) ParseOption {
+	return func(o *parseOptions) {
+		o.warn = fn
+	}
+}
+
+// ErrUnsupportedElement is the cause of warnings for the elements that are
+// dropped by the parser
+var ErrUnsupportedElement = errors.New("unsupported element")
+
+func Parse(in string, opts ...ParseOption) (*Svg, error) {
+	ctx := &parseContext{in: in}
+	for _, o := range opts {
+		o(&ctx.opts)
+	}
+	ctx.scan()
+
 	content := xg.Open(in)
-	if !content.NextTag() {
-		err := content.Err()
-		if err == nil {
-			err = errors.New("invalid file content")
+	prolog := strings.Builder{}
+	for !content.IsTag() {
+		if !content.Next() {
+			if err := content.Err(); err != nil {
+				return nil, ctx.syntaxError("", err)
+			}
+			return nil, &ParseError{Err: errors.New("invalid file content")}
+		}
+		if !content.IsTag() {
+			prolog.WriteString(rawToken(content))
 		}
-		return nil, err
 	}
-	if content.Name() != "svg" {
-		return nil, content.MakeError("", "root tag must be 'svg'")
+	if name := string(content.Name()); name != "svg" {
+		pe := &ParseError{Element: name, Err: errors.New("root tag must be 'svg'")}
+		if pos := ctx.tagPos(0); pos >= 0 {
+			pe.Line, pe.Column = xg.CalcLocation(in, pos)
+			pe.Line++
+			pe.Column++
+		}
+		return nil, pe
 	}
-	s := &Svg{}
+	s := &Svg{prolog: strings.TrimLeft(prolog.String(), " \t\r\n")}
+	index := ctx.enter()
 	content.HandleTag(func(aa xg.AttributeList, cc *xg.Content) error {
-		return s.read(&xgsourcer{aa, cc})
+		return readItem(s, ctx.sourcer("svg", nil, aa, cc, index))
 	})
-	if content.Err() != nil {
-		return nil, content.Err()
+	epilog := strings.Builder{}
+	for content.Err() == nil && content.Next() {
+		epilog.WriteString(rawToken(content))
+	}
+	if err := content.Err(); err != nil {
+		return nil, ctx.syntaxError("", err)
 	}
+	s.epilog = epilog.String()
 
+	s.Resolve()
 	return s, nil
 }
 
+// rawToken returns the markup of the current token with its leading
+// whitespace
+func rawToken(cc *xg.Content) string {
+	wp, raw := cc.Raw()
+	return wp + raw
+}
+
+// parseContext holds the state shared by all sourcers of a document
+type parseContext struct {
+	in    string
+	opts  parseOptions
+	sheet *styleSheet
+	tags  []tagSpan // elements of the document in document order
+	next  int       // index of the next element in tags
+}
+
+// tagSpan locates an element within the document
+type tagSpan struct {
+	pos int // byte offset of the start tag
+	end int // index of the element that follows the descendants
+}
+
+// scan collects the positions of the elements, and extracts and parses the
+// content of all <style> elements in the document, so that the rules can be
+// applied to elements that precede the style sheet
+func (ctx *parseContext) scan() {
+	css := ""
+	stack := []int{}
+	inStyle := func() bool {
+		return len(stack) > 0 && ctx.tags[stack[len(stack)-1]].end < 0
+	}
+	xg.ParseTokens(ctx.in, func(t *xg.Token) error {
+		switch t.Kind {
+		case xg.Tag:
+			stack = append(stack, len(ctx.tags))
+			end := 0
+			if t.Name == "style" {
+				end = -1 // marks <style> until it is closed
+			}
+			ctx.tags = append(ctx.tags, tagSpan{pos: t.SrcPos, end: end})
+		case xg.CloseEmptyTag, xg.EndContent:
+			if len(stack) > 0 {
+				ctx.tags[stack[len(stack)-1]].end = len(ctx.tags)
+				stack = stack[:len(stack)-1]
+			}
+		case xg.SData:
+			if inStyle() {
+				css += t.Value.Unscrambled()
+			}
+		case xg.CData:
+			if inStyle() {
+				css += string(t.Value)
+			}
+		}
+		return nil
+	})
+	if strings.TrimSpace(css) != "" {
+		ctx.sheet = parseStyleSheet(css)
+	}
+}
+
+// enter is called before an element is handled, returns the index of the
+// element within tags
+func (ctx *parseContext) enter() (index int) {
+	index = ctx.next
+	ctx.next++
+	return
+}
+
+// leave is called after an element is handled, the descendants of the
+// element that have not been visited are skipped
+func (ctx *parseContext) leave(index int) {
+	if index < len(ctx.tags) && ctx.tags[index].end > ctx.next {
+		ctx.next = ctx.tags[index].end
+	}
+}
+
+// tagPos returns the byte offset of the start tag of an element, or -1 if
+// the element is not known
+func (ctx *parseContext) tagPos(index int) int {
+	if index < len(ctx.tags) {
+		return ctx.tags[index].pos
+	}
+	return -1
+}
+
+func (ctx *parseContext) sourcer(tag string, parent *cssElement, aa xg.AttributeList, cc *xg.Content, index int) *xgsourcer {
+	el := &cssElement{tag: tag, parent: parent}
+	el.id, _ = aa.Attr("id")
+	if c, ok := aa.Attr("class"); ok {
+		el.classes = strings.Fields(c)
+	}
+	return &xgsourcer{ctx: ctx, el: el, aa: aa, cc: cc, index: index,
+		usedAttrs: map[string]bool{}, usedProps: map[string]bool{}}
+}
+
 type xgsourcer struct {
-	aa xg.AttributeList
-	cc *xg.Content
+	ctx       *parseContext
+	el        *cssElement
+	aa        xg.AttributeList
+	cc        *xg.Content
+	index     int // of the element within ctx.tags
+	lastAttr  string
+	usedAttrs map[string]bool
+	usedProps map[string]bool
+	sources   map[string]propertySource
+	rejected  map[string]bool // attributes dropped in lenient mode
+	consumed  bool            // set once the content is read
+	pending   []*ParseError   // warnings of the current attempt to read the element
+	kept      int             // number of pending warnings that survive a retry
 }
 
 func (x *xgsourcer) Attr(name string) (v string, exists bool) {
-	return x.aa.Attr(name)
+	if x.rejected[name] {
+		return "", false
+	}
+	v, exists = x.aa.Attr(name)
+	if exists {
+		x.usedAttrs[name] = true
+		x.lastAttr = name
+	}
+	return
+}
+
+func (x *xgsourcer) useProperty(name string) {
+	x.usedProps[name] = true
+}
+
+func (x *xgsourcer) keepAttr(name string) {
+	delete(x.usedAttrs, name)
+}
+
+func (x *xgsourcer) setPropertySource(name string, s propertySource) {
+	if x.sources == nil {
+		x.sources = map[string]propertySource{}
+	}
+	x.sources[name] = s
+}
+
+func (x *xgsourcer) propertySources() map[string]propertySource {
+	return x.sources
+}
+
+func (x *xgsourcer) unused() (attrs []Attribute, style []declaration) {
+	for _, a := range x.aa {
+		n := string(a.Name)
+		if x.rejected[n] {
+			continue
+		}
+		if x.usedAttrs[n] {
+			if n == "style" {
+				for _, d := range parseDeclarations(a.Value.Unscrambled()) {
+					if !x.usedProps[d.Property] {
+						style = append(style, d)
+					}
+				}
+			}
+			continue
+		}
+		attrs = append(attrs, Attribute{Name: n, Value: a.Value.Unscrambled()})
+	}
+	return
 }
 
 func (x *xgsourcer) ForEachChildNode(callback func(tag string, ch sourcer) error) error {
+	return x.ForEachChild(callback, nil)
+}
+
+func (x *xgsourcer) ForEachChild(onNode func(tag string, ch sourcer) error, onText func(text string) error) error {
+	return x.ForEachContent(onNode, onText, nil)
+}
+
+func (x *xgsourcer) ForEachContent(onNode func(tag string, ch sourcer) error, onText func(text string) error, onComment func(text string) error) error {
+	x.consumed = true
+	x.flush()
 	if x.cc == nil {
 		return nil
 	}
-	for x.cc.NextTag() {
-		n := string(x.cc.Name())
-		x.cc.HandleTag(func(aa xg.AttributeList, cc *xg.Content) error {
-			return callback(n, &xgsourcer{aa, cc})
-		})
+	for x.cc.Next() {
+		var err error
+		switch {
+		case x.cc.IsTag():
+			index := x.ctx.enter()
+			n := string(x.cc.Name())
+			x.cc.HandleTag(func(aa xg.AttributeList, cc *xg.Content) error {
+				cs := x.ctx.sourcer(n, x.el, aa, cc, index)
+				if onNode == nil {
+					cs.dropElement()
+					return nil
+				}
+				return onNode(n, cs)
+			})
+			x.ctx.leave(index)
+		case x.cc.IsSData():
+			if onText != nil {
+				err = onText(x.cc.Value().Unscrambled())
+			}
+		case x.cc.IsCData():
+			if onText != nil {
+				err = onText(string(x.cc.Value()))
+			}
+		case x.cc.IsComment():
+			if onComment != nil {
+				err = onComment(string(x.cc.Value()))
+			}
+		}
+		if err != nil {
+			return err
+		}
 		if x.cc.Err() != nil {
-			return x.cc.Err()
+			return x.syntaxError(x.cc.Err())
 		}
 	}
-	return nil
+	return x.syntaxError(x.cc.Err())
+}
+
+func (x *xgsourcer) RawContent() (string, error) {
+	x.consumed = true
+	x.flush()
+	sb := strings.Builder{}
+	err := writeRawContent(&sb, x.cc)
+	return sb.String(), x.syntaxError(err)
+}
+
+// writeRawContent reproduces the markup of the content, attributes of
+// nested elements are kept with their original quoting and whitespace
+func writeRawContent(sb *strings.Builder, cc *xg.Content) error {
+	if cc == nil {
+		return nil
+	}
+	for cc.Next() {
+		wp, raw := cc.Raw()
+		sb.WriteString(wp)
+		sb.WriteString(raw)
+		if cc.IsTag() {
+			name := string(cc.Name())
+			cc.HandleTag(func(aa xg.AttributeList, ch *xg.Content) error {
+				for _, a := range aa {
+					sb.WriteString(a.WhitePrefix)
+					sb.WriteString(a.Raw)
+				}
+				if ch == nil {
+					sb.WriteString("/>")
+					return nil
+				}
+				sb.WriteString(">")
+				if err := writeRawContent(sb, ch); err != nil {
+					return err
+				}
+				sb.WriteString("</" + name + ">")
+				return nil
+			})
+		}
+		if cc.Err() != nil {
+			return cc.Err()
+		}
+	}
+	return cc.Err()
+}
+
+func (x *xgsourcer) SheetDeclarations() []declaration {
+	return x.ctx.sheet.match(x.el)
 }
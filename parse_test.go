@@ -1,6 +1,9 @@
 package svg
 
 import (
+	"bytes"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -29,3 +32,201 @@ func TestParser(t *testing.T) {
 		return
 	}
 }
+
+func TestStrokeProperties(t *testing.T) {
+	data := `<svg viewBox="0 0 16 16">
+		<path d="M1,1L15,15" stroke="#f00" stroke-width="2" stroke-opacity="0.5"
+			stroke-linecap="round" stroke-linejoin="bevel" stroke-miterlimit="8"
+			stroke-dasharray="4, 2 1" stroke-dashoffset="1.5"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := doc.Items[0].(*Path)
+	if p.Stroke == nil || p.Stroke.Kind != PaintKindRGB || p.Stroke.Color != (RGB{0xff, 0, 0}) {
+		t.Errorf("stroke = %v", p.Stroke)
+	}
+	if p.StrokeWidth != "2" {
+		t.Errorf("stroke-width = %q", p.StrokeWidth)
+	}
+	if p.StrokeOpacity == nil || *p.StrokeOpacity != 0.5 {
+		t.Errorf("stroke-opacity = %v", p.StrokeOpacity)
+	}
+	if p.StrokeLineCap == nil || *p.StrokeLineCap != LineCapRound {
+		t.Errorf("stroke-linecap = %v", p.StrokeLineCap)
+	}
+	if p.StrokeLineJoin == nil || *p.StrokeLineJoin != LineJoinBevel {
+		t.Errorf("stroke-linejoin = %v", p.StrokeLineJoin)
+	}
+	if p.StrokeMiterLimit == nil || *p.StrokeMiterLimit != 8 {
+		t.Errorf("stroke-miterlimit = %v", p.StrokeMiterLimit)
+	}
+	if p.StrokeDashArray == nil || p.StrokeDashArray.String() != "4,2,1" {
+		t.Errorf("stroke-dasharray = %v", p.StrokeDashArray)
+	}
+	if p.StrokeDashOffset != "1.5" {
+		t.Errorf("stroke-dashoffset = %q", p.StrokeDashOffset)
+	}
+
+	for _, attr := range []string{
+		`stroke-linecap="pointy"`, `stroke-miterlimit="0.5"`,
+		`stroke-dasharray="1,-2"`, `stroke-width="wide"`,
+	} {
+		if _, err := Parse(`<svg><path ` + attr + `/></svg>`); err == nil {
+			t.Errorf("%s: expected an error", attr)
+		}
+	}
+}
+
+func TestStyleAttribute(t *testing.T) {
+	data := `<svg>
+		<g style="opacity:0.5">
+			<rect fill="#000" stroke-width="1" style="fill: #fff; /* note */ stroke-width:2px;enable-background:new"/>
+		</g>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := doc.Items[0].(*Group)
+	if g.Opacity == nil || *g.Opacity != 0.5 {
+		t.Errorf("group opacity = %v", g.Opacity)
+	}
+	r := g.Items[0].(*Rect)
+	if r.Fill == nil || r.Fill.Color != (RGB{0xff, 0xff, 0xff}) {
+		t.Errorf("style declaration does not override the fill attribute: %v", r.Fill)
+	}
+	if r.StrokeWidth != "2px" {
+		t.Errorf("stroke-width = %q", r.StrokeWidth)
+	}
+
+	var warning *ParseError
+	doc, err = Parse(`<svg><rect fill="blue" style="fill:#zzz; stroke:red"/></svg>`, WithWarnings(func(w *ParseError) {
+		warning = w
+	}))
+	if err != nil {
+		t.Fatalf("invalid style declaration is not ignored: %v", err)
+	}
+	r = doc.Items[0].(*Rect)
+	if r.Fill == nil || r.Fill.Color != (RGB{0, 0, 0xff}) || r.Stroke == nil {
+		t.Errorf("fill = %v, stroke = %v", r.Fill, r.Stroke)
+	}
+	if warning == nil || warning.Attr != "style" {
+		t.Errorf("warning = %v", warning)
+	}
+}
+
+func TestUnsupportedPaint(t *testing.T) {
+	data := `<svg>
+		<path d="M0 0" fill="context-stroke" stroke="oklch(70% 0.1 200)" style="fill:red;stroke:var(--c)"/>
+	</svg>`
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := doc.Items[0].(*Path)
+	if p.Fill == nil || p.Fill.Color != (RGB{0xff, 0, 0}) || p.Stroke != nil {
+		t.Errorf("fill = %v, stroke = %v", p.Fill, p.Stroke)
+	}
+	buf := bytes.Buffer{}
+	if err = Write(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`fill="context-stroke"`, `stroke="oklch(70% 0.1 200)"`, "stroke:var(--c)"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("%s is not kept:\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestPresentationValues(t *testing.T) {
+	data := `<svg>
+		<rect fill="inherit" stroke=" red " stroke-width="inherit" opacity="50%" fill-opacity=" 0.25 "/>
+	</svg>`
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := doc.Items[0].(*Rect)
+	if r.Stroke == nil || r.Stroke.Color != (RGB{0xff, 0, 0}) {
+		t.Errorf("stroke = %v", r.Stroke)
+	}
+	if r.Opacity == nil || *r.Opacity != 0.5 {
+		t.Errorf("opacity = %v", r.Opacity)
+	}
+	if r.FillOpacity == nil || *r.FillOpacity != 0.25 {
+		t.Errorf("fill-opacity = %v", r.FillOpacity)
+	}
+	if r.Fill != nil || r.StrokeWidth != "" {
+		t.Errorf("fill = %v, stroke-width = %q", r.Fill, r.StrokeWidth)
+	}
+	want := []Attribute{{Name: "fill", Value: "inherit"}, {Name: "stroke-width", Value: "inherit"}}
+	if !reflect.DeepEqual(r.ExtraAttrs, want) {
+		t.Errorf("extra attributes = %v, want %v", r.ExtraAttrs, want)
+	}
+}
+
+func TestParseDeclarations(t *testing.T) {
+	got := parseDeclarations(` fill : red ; ;junk; stroke:url("a;b") !important;`)
+	want := []declaration{
+		{Property: "fill", Value: "red"},
+		{Property: "stroke", Value: `url("a;b")`, Important: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got[i], want[i])
+		}
+	}
+}
+
+func TestDefsAndByID(t *testing.T) {
+	data := `<svg id="root">
+		<defs id="d">
+			<path id="p1" d="M0,0"/>
+			<g id="dup"/>
+		</defs>
+		<g id="g1">
+			<rect id="dup"/>
+			<circle id="c1"/>
+		</g>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs, ok := doc.Items[0].(*Defs)
+	if !ok || len(defs.Items) != 2 {
+		t.Fatalf("defs are not parsed")
+	}
+	if doc.ByID("root") != doc {
+		t.Errorf("root is not indexed")
+	}
+	if doc.ByID("p1") != defs.Items[0] {
+		t.Errorf("ByID(p1) = %v", doc.ByID("p1"))
+	}
+	if _, ok := doc.ByID("c1").(*Circle); !ok {
+		t.Errorf("ByID(c1) = %v", doc.ByID("c1"))
+	}
+	if _, ok := doc.ByID("dup").(*Group); !ok {
+		t.Errorf("first element with duplicate id should win")
+	}
+	if doc.ByID("missing") != nil {
+		t.Errorf("ByID(missing) should be nil")
+	}
+	if dd := doc.DuplicateIDs(); len(dd) != 1 || dd[0] != "dup" {
+		t.Errorf("DuplicateIDs() = %v", dd)
+	}
+
+	g := doc.Items[1].(*Group)
+	g.Items = g.Items[1:]
+	if dd := doc.Reindex(); len(dd) != 0 {
+		t.Errorf("Reindex() = %v", dd)
+	}
+}
@@ -4,6 +4,10 @@ package svg
 type PathData struct {
 	Commands []PathCommand
 	Vertices []Vertex
+
+	// arcs holds the indices of the commands that continue an elliptical
+	// arc, which is approximated with several curves
+	arcs map[int]bool
 }
 
 type Vertex = Vector
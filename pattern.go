@@ -0,0 +1,120 @@
+package svg
+
+import "fmt"
+
+// Pattern implements SVG <pattern> element, a paint server that tiles its
+// content over the painted area
+//
+// Attributes and content that are not specified are inherited from the
+// template pattern referenced with Href, use Resolve to obtain the effective
+// values.
+type Pattern struct {
+	Node
+	Presentation
+	Href                string
+	Units               GradientUnits // patternUnits, objectBoundingBox when unspecified
+	ContentUnits        GradientUnits // patternContentUnits, userSpaceOnUse when unspecified
+	PatternTransform    *Transform
+	ViewBox             *ViewBoxValue
+	PreserveAspectRatio *PreserveAspectRatio
+	X                   Coordinate
+	Y                   Coordinate
+	Width               Length
+	Height              Length
+}
+
+func (p *Pattern) paintServer() {}
+
+func (p *Pattern) read(src sourcer) (err error) {
+	err = p.item.read(src)
+	if err != nil {
+		return
+	}
+	err = p.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	p.Href = p.readHref(src)
+	if v, exists := src.Attr("patternUnits"); exists {
+		if err = p.Units.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid patternUnits: %w", err)
+		}
+	}
+	if v, exists := src.Attr("patternContentUnits"); exists {
+		if err = p.ContentUnits.Unmarshal(v); err != nil {
+			return fmt.Errorf("invalid patternContentUnits: %w", err)
+		}
+	}
+	if v, exists := src.Attr("patternTransform"); exists {
+		p.PatternTransform, err = parseTransformAttr(v)
+		if err != nil {
+			return fmt.Errorf("invalid patternTransform: %w", err)
+		}
+	}
+	err = readViewport(src, &p.ViewBox, &p.PreserveAspectRatio,
+		&p.X, &p.Y, &p.Width, &p.Height)
+	if err != nil {
+		return
+	}
+	return readChildren(src, &p.Items)
+}
+
+func (p *Pattern) write(tgt targeter) {
+	p.item.write(tgt)
+	p.Presentation.write(tgt)
+	if len(p.Href) > 0 {
+		p.writeHref(tgt, p.Href)
+	}
+	tgt.Attr("patternUnits", p.Units.String())
+	tgt.Attr("patternContentUnits", p.ContentUnits.String())
+	if p.PatternTransform != nil {
+		tgt.Attr("patternTransform", p.PatternTransform.String())
+	}
+	writeViewport(tgt, p.ViewBox, p.PreserveAspectRatio, p.X, p.Y, p.Width, p.Height)
+	p.writeItems(tgt)
+}
+
+// Resolve returns a copy of the pattern, in which attributes and content
+// that are not specified are inherited from the chain of href templates
+func (p *Pattern) Resolve(doc *Svg) *Pattern {
+	ret := *p
+	visited := map[*Pattern]bool{p: true}
+	for t := p; ; {
+		t, _ = doc.ByID(hrefID(t.Href)).(*Pattern)
+		if t == nil || visited[t] {
+			break
+		}
+		visited[t] = true
+		if ret.Units == GradientUnitsUnspecified {
+			ret.Units = t.Units
+		}
+		if ret.ContentUnits == GradientUnitsUnspecified {
+			ret.ContentUnits = t.ContentUnits
+		}
+		if ret.PatternTransform == nil {
+			ret.PatternTransform = t.PatternTransform
+		}
+		if ret.ViewBox == nil {
+			ret.ViewBox = t.ViewBox
+		}
+		if ret.PreserveAspectRatio == nil {
+			ret.PreserveAspectRatio = t.PreserveAspectRatio
+		}
+		if ret.X == "" {
+			ret.X = t.X
+		}
+		if ret.Y == "" {
+			ret.Y = t.Y
+		}
+		if ret.Width == "" {
+			ret.Width = t.Width
+		}
+		if ret.Height == "" {
+			ret.Height = t.Height
+		}
+		if len(ret.Items) == 0 {
+			ret.Items = t.Items
+		}
+	}
+	return &ret
+}
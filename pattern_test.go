@@ -0,0 +1,66 @@
+package svg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPattern(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24">
+		<defs>
+			<pattern id="hatch" patternUnits="userSpaceOnUse" patternContentUnits="userSpaceOnUse"
+				patternTransform="rotate(45)" width="4" height="4" viewBox="0 0 2 2"
+				preserveAspectRatio="none" stroke="#999">
+				<line x1="0" y1="0" x2="0" y2="2"/>
+			</pattern>
+			<pattern id="wide" href="#hatch" width="8"/>
+			<pattern id="loop" href="#loop"/>
+		</defs>
+		<rect id="r1" width="24" height="24" fill="url(#hatch)" stroke="url(#wide) gray"/>
+		<rect id="r2" fill="url(#loop)"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hatch := doc.ByID("hatch").(*Pattern)
+	if hatch.Units != GradientUnitsUserSpaceOnUse || hatch.ContentUnits != GradientUnitsUserSpaceOnUse ||
+		hatch.PatternTransform == nil || hatch.ViewBox == nil || hatch.ViewBox.Width != 2 ||
+		hatch.PreserveAspectRatio == nil || hatch.PreserveAspectRatio.Align != AlignNone ||
+		hatch.Width != "4" || hatch.Stroke == nil || len(hatch.Items) != 1 {
+		t.Errorf("pattern = %+v", hatch)
+	}
+
+	r1 := doc.ByID("r1").(*Rect)
+	if r1.Fill.Kind != PaintKindPattern || r1.Fill.Server != hatch {
+		t.Errorf("fill = %+v", r1.Fill)
+	}
+	if r1.Stroke.Kind != PaintKindPattern || r1.Stroke.Server != doc.ByID("wide") {
+		t.Errorf("stroke = %+v", r1.Stroke)
+	}
+
+	wide := doc.ByID("wide").(*Pattern).Resolve(doc)
+	if wide.Width != "8" || wide.Height != "4" || wide.Units != GradientUnitsUserSpaceOnUse ||
+		wide.ViewBox != hatch.ViewBox || len(wide.Items) != 1 || wide.Items[0] != hatch.Items[0] {
+		t.Errorf("resolved pattern = %+v", wide)
+	}
+	if loop := doc.ByID("loop").(*Pattern).Resolve(doc); loop.Width != "" {
+		t.Errorf("resolved loop = %+v", loop)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+
+	for _, in := range []string{
+		`<svg><pattern patternUnits="tile"/></svg>`,
+		`<svg><pattern patternTransform="spin(1)"/></svg>`,
+		`<svg><pattern viewBox="0 0 1"/></svg>`,
+	} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
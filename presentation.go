@@ -0,0 +1,385 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Presentation holds SVG presentation properties. These can be specified
+// with presentation attributes, with style sheet rules, or with CSS
+// declarations within the style attribute, in the order of increasing
+// precedence.
+type Presentation struct {
+	Fill             *Paint
+	FillRule         *FillRule
+	FillOpacity      *float64
+	Stroke           *Paint
+	StrokeWidth      Length
+	StrokeOpacity    *float64
+	StrokeLineCap    *LineCap
+	StrokeLineJoin   *LineJoin
+	StrokeMiterLimit *float64
+	StrokeDashArray  *DashArray
+	StrokeDashOffset Length
+	Opacity          *float64
+	FontFamily       string
+	FontSize         Length // a length, or a keyword such as small or larger
+	FontWeight       string
+	TextAnchor       *TextAnchor
+	DominantBaseline *DominantBaseline
+	ClipPath         *Reference
+	ClipRule         *FillRule
+	Mask             *Reference
+	MarkerStart      *Reference
+	MarkerMid        *Reference
+	MarkerEnd        *Reference
+	Overflow         *Overflow
+	Filter           *Reference
+}
+
+func (p *Presentation) presentation() *Presentation {
+	return p
+}
+
+// presentationProperties lists supported properties in the order they are
+// written
+var presentationProperties = []string{
+	"fill",
+	"fill-rule",
+	"fill-opacity",
+	"stroke",
+	"stroke-width",
+	"stroke-opacity",
+	"stroke-linecap",
+	"stroke-linejoin",
+	"stroke-miterlimit",
+	"stroke-dasharray",
+	"stroke-dashoffset",
+	"opacity",
+	"font-family",
+	"font-size",
+	"font-weight",
+	"text-anchor",
+	"dominant-baseline",
+	"clip-path",
+	"clip-rule",
+	"mask",
+	"marker-start",
+	"marker-mid",
+	"marker-end",
+	"overflow",
+	"filter",
+}
+
+// set assigns a property value by property name, known is false for
+// properties that are not supported
+func (p *Presentation) set(name, v string) (known bool, err error) {
+	known = true
+	switch name {
+	case "fill":
+		p.Fill, err = ParsePaint(v)
+	case "fill-rule":
+		r := FillRuleInherit
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			p.FillRule = &r
+		}
+	case "fill-opacity":
+		p.FillOpacity, err = ParseOpacity(v)
+	case "stroke":
+		p.Stroke, err = ParsePaint(v)
+	case "stroke-width":
+		if _, _, err = Length(v).AsNumeric(); err == nil {
+			p.StrokeWidth = Length(v)
+		}
+	case "stroke-opacity":
+		p.StrokeOpacity, err = ParseOpacity(v)
+	case "stroke-linecap":
+		r := LineCapButt
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			p.StrokeLineCap = &r
+		}
+	case "stroke-linejoin":
+		r := LineJoinMiter
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			p.StrokeLineJoin = &r
+		}
+	case "stroke-miterlimit":
+		var ml *float64
+		ml, err = ParseNumber(v)
+		if err == nil && *ml < 1 {
+			err = errors.New("value must be at least 1")
+		}
+		if err == nil {
+			p.StrokeMiterLimit = ml
+		}
+	case "stroke-dasharray":
+		p.StrokeDashArray, err = ParseDashArray(v)
+	case "stroke-dashoffset":
+		if _, _, err = Length(v).AsNumeric(); err == nil {
+			p.StrokeDashOffset = Length(v)
+		}
+	case "opacity":
+		p.Opacity, err = ParseOpacity(v)
+	case "font-family":
+		p.FontFamily = v
+	case "font-size":
+		if err = validFontSize(v); err == nil {
+			p.FontSize = Length(v)
+		}
+	case "font-weight":
+		if err = validFontWeight(v); err == nil {
+			p.FontWeight = v
+		}
+	case "text-anchor":
+		r := TextAnchorStart
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			p.TextAnchor = &r
+		}
+	case "dominant-baseline":
+		r := DominantBaselineAuto
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			p.DominantBaseline = &r
+		}
+	case "clip-path":
+		p.ClipPath, err = ParseReference(v)
+	case "clip-rule":
+		r := FillRuleInherit
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			p.ClipRule = &r
+		}
+	case "mask":
+		p.Mask, err = ParseReference(v)
+	case "marker-start":
+		p.MarkerStart, err = ParseReference(v)
+	case "marker-mid":
+		p.MarkerMid, err = ParseReference(v)
+	case "marker-end":
+		p.MarkerEnd, err = ParseReference(v)
+	case "overflow":
+		r := OverflowVisible
+		if err = r.UnmarshalText([]byte(v)); err == nil {
+			p.Overflow = &r
+		}
+	case "filter":
+		p.Filter, err = ParseReference(v)
+	case "marker":
+		// shorthand, only valid in CSS, sets all marker properties
+		var r *Reference
+		if r, err = ParseReference(v); err == nil {
+			p.MarkerStart, p.MarkerMid, p.MarkerEnd = r, &Reference{r.ID}, &Reference{r.ID}
+		}
+	default:
+		known = false
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return
+}
+
+// get returns a property value formatted in SVG syntax, or an empty string
+// if the property is not specified
+func (p *Presentation) get(name string) string {
+	switch name {
+	case "fill":
+		if p.Fill != nil {
+			return p.Fill.String()
+		}
+	case "fill-rule":
+		if p.FillRule != nil {
+			return p.FillRule.String()
+		}
+	case "fill-opacity":
+		if p.FillOpacity != nil {
+			return formatNumber(*p.FillOpacity)
+		}
+	case "stroke":
+		if p.Stroke != nil {
+			return p.Stroke.String()
+		}
+	case "stroke-width":
+		return string(p.StrokeWidth)
+	case "stroke-opacity":
+		if p.StrokeOpacity != nil {
+			return formatNumber(*p.StrokeOpacity)
+		}
+	case "stroke-linecap":
+		if p.StrokeLineCap != nil {
+			return p.StrokeLineCap.String()
+		}
+	case "stroke-linejoin":
+		if p.StrokeLineJoin != nil {
+			return p.StrokeLineJoin.String()
+		}
+	case "stroke-miterlimit":
+		if p.StrokeMiterLimit != nil {
+			return formatNumber(*p.StrokeMiterLimit)
+		}
+	case "stroke-dasharray":
+		if p.StrokeDashArray != nil {
+			return p.StrokeDashArray.String()
+		}
+	case "stroke-dashoffset":
+		return string(p.StrokeDashOffset)
+	case "opacity":
+		if p.Opacity != nil {
+			return formatNumber(*p.Opacity)
+		}
+	case "font-family":
+		return p.FontFamily
+	case "font-size":
+		return string(p.FontSize)
+	case "font-weight":
+		return p.FontWeight
+	case "text-anchor":
+		if p.TextAnchor != nil {
+			return p.TextAnchor.String()
+		}
+	case "dominant-baseline":
+		if p.DominantBaseline != nil {
+			return p.DominantBaseline.String()
+		}
+	case "clip-path":
+		if p.ClipPath != nil {
+			return p.ClipPath.String()
+		}
+	case "clip-rule":
+		if p.ClipRule != nil {
+			return p.ClipRule.String()
+		}
+	case "mask":
+		if p.Mask != nil {
+			return p.Mask.String()
+		}
+	case "marker-start":
+		if p.MarkerStart != nil {
+			return p.MarkerStart.String()
+		}
+	case "marker-mid":
+		if p.MarkerMid != nil {
+			return p.MarkerMid.String()
+		}
+	case "marker-end":
+		if p.MarkerEnd != nil {
+			return p.MarkerEnd.String()
+		}
+	case "overflow":
+		if p.Overflow != nil {
+			return p.Overflow.String()
+		}
+	case "filter":
+		if p.Filter != nil {
+			return p.Filter.String()
+		}
+	}
+	return ""
+}
+
+func (p *Presentation) read(src sourcer) (err error) {
+	return readProperties(src, presentationProperties, p.set)
+}
+
+// readProperties assigns property values specified with presentation
+// attributes, style sheet rules, and the style attribute. These are applied
+// in the cascade order, so that the latter take precedence. The origins of
+// the values that are not specified with presentation attributes are
+// recorded, so that these can be written back to the same places.
+func readProperties(src sourcer, names []string, set func(name, v string) (known bool, err error)) (err error) {
+	set = tolerant(set)
+	// the values that are set, restored when a later value is dropped
+	applied := map[string]string{}
+	attrs := map[string]string{}
+	for _, name := range names {
+		if v, exists := src.Attr(name); exists {
+			if _, err = set(name, v); errors.Is(err, errUnsupported) {
+				src.keepAttr(name)
+				err = nil
+				continue
+			} else if err != nil {
+				if err = src.reject(name, err); err != nil {
+					return
+				}
+				continue
+			}
+			applied[name] = v
+			attrs[name] = v
+		}
+	}
+
+	inline := []declaration{}
+	if v, exists := src.Attr("style"); exists {
+		inline = parseDeclarations(v)
+	}
+	for _, d := range cascade(src.SheetDeclarations(), inline) {
+		known := false
+		if known, err = set(d.Property, d.Value); err != nil {
+			if errors.Is(err, errUnsupported) {
+				// kept with the unused declarations
+				known, err = false, nil
+			} else {
+				// invalid declarations are ignored, as CSS requires
+				attr := ""
+				if d.inline {
+					attr = "style"
+				}
+				src.warnAttr(attr, fmt.Errorf("invalid style: %w", err))
+				err = nil
+			}
+			if prev, ok := applied[d.Property]; ok {
+				set(d.Property, prev)
+			}
+		} else {
+			applied[d.Property] = d.Value
+			if known {
+				src.setPropertySource(d.Property, propertySource{
+					sheet:     !d.inline,
+					important: d.Important,
+					attr:      attrs[d.Property],
+				})
+			}
+		}
+		if known {
+			src.useProperty(d.Property)
+		}
+	}
+	return
+}
+
+// tolerant wraps a property setter, the values are trimmed, and CSS-wide
+// keywords that the setter does not accept are reported as unsupported
+func tolerant(set func(name, v string) (known bool, err error)) func(name, v string) (known bool, err error) {
+	return func(name, v string) (known bool, err error) {
+		v = strings.TrimSpace(v)
+		known, err = set(name, v)
+		if err != nil && cssWideKeywords[v] {
+			err = fmt.Errorf("%w '%s'", errUnsupported, v)
+		}
+		return
+	}
+}
+
+var cssWideKeywords = map[string]bool{
+	"inherit": true, "initial": true, "unset": true, "revert": true, "revert-layer": true,
+}
+
+// errUnsupported is returned for the values that are valid, but can not be
+// represented, these are kept with the item as they are
+var errUnsupported = errors.New("unsupported value")
+
+// propertySource records where the value of a property comes from, the
+// values of presentation attributes are not recorded
+type propertySource struct {
+	sheet     bool   // applied by the style sheet, otherwise by the style attribute
+	important bool   // declared with !important
+	value     string // formatted value, to detect the values changed after reading
+	attr      string // value of the overridden presentation attribute, if any
+}
+
+func (p *Presentation) write(tgt targeter) {
+	for _, name := range presentationProperties {
+		if v := p.get(name); len(v) > 0 {
+			tgt.Property(name, v)
+		}
+	}
+}
@@ -1,11 +1,87 @@
 package svg
 
+import "strings"
+
 type sourcer interface {
 	Attr(name string) (v string, exists bool)
 	ForEachChildNode(callback func(tag string, ch sourcer) error) error
+	ForEachChild(onNode func(tag string, ch sourcer) error, onText func(text string) error) error
+
+	// ForEachContent is ForEachChild that also reports XML comments
+	ForEachContent(onNode func(tag string, ch sourcer) error, onText func(text string) error, onComment func(text string) error) error
+
+	// RawContent returns the content of the element as XML markup
+	RawContent() (string, error)
+
+	// SheetDeclarations returns declarations of the document style sheet
+	// rules that match the element, in cascade order
+	SheetDeclarations() []declaration
+
+	// useProperty marks a property as supported by the reader
+	useProperty(name string)
+
+	// keepAttr marks an attribute as not used by the reader, so that it is
+	// kept with the item as it is
+	keepAttr(name string)
+
+	// setPropertySource records the origin of a property value that is
+	// specified with a style sheet rule or with the style attribute
+	setPropertySource(name string, s propertySource)
+
+	// propertySources returns the recorded origins
+	propertySources() map[string]propertySource
+
+	// unused returns the attributes that have not been accessed by the
+	// reader, and the declarations of the style attribute for properties
+	// that are not supported
+	unused() (attrs []Attribute, style []declaration)
+
+	// fail converts an error of the reader into *ParseError
+	fail(err error) error
+
+	// reject handles an invalid value, returns nil if it is dropped
+	reject(attr string, err error) error
+
+	// recover reports whether the element can be read again after the
+	// failure, with the offending attribute dropped
+	recover(err error) bool
+
+	// dropElement reports the element as dropped
+	dropElement()
+
+	// flush reports the warnings held for the element
+	flush()
+
+	// warnAttr reports an attribute value that is kept, but can not be
+	// interpreted
+	warnAttr(attr string, err error)
 }
 
 type reader interface {
 	Item
 	read(src sourcer) error
 }
+
+// readHref returns the value of href attribute, or its xlink:href
+// equivalent that was used prior to SVG 2. The name of the attribute is
+// kept, so that the same one is written back.
+func (it *item) readHref(src sourcer) string {
+	if v, exists := src.Attr("href"); exists {
+		return v
+	}
+	v, exists := src.Attr("xlink:href")
+	if exists {
+		it.hrefAttr = "xlink:href"
+	}
+	return v
+}
+
+// hrefID extracts the id from a local IRI reference such as "#id", returns
+// an empty string for references to external resources
+func hrefID(href string) string {
+	href = strings.TrimSpace(href)
+	if !strings.HasPrefix(href, "#") {
+		return ""
+	}
+	return href[1:]
+}
@@ -2,39 +2,327 @@ package svg
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 func ParsePaint(s string) (*Paint, error) {
+	s = strings.TrimSpace(s)
 	if len(s) == 0 {
 		return nil, fmt.Errorf("empty specs")
 	}
-	if s == "none" {
+	if isFuncIRI(s) {
+		return parsePaintURL(s)
+	}
+	switch strings.ToLower(s) {
+	case "none":
 		return &Paint{Kind: PaintKindNone}, nil
+	case "currentcolor":
+		return &Paint{Kind: PaintKindCurrentColor}, nil
+	case "context-fill", "context-stroke":
+		return nil, fmt.Errorf("%w '%s'", errUnsupported, s)
+	}
+	return parseColorPaint(s)
+}
+
+// parseColorPaint parses the value of a color property such as stop-color,
+// which is either a color or currentColor, paint servers and none are not
+// allowed
+func parseColorPaint(s string) (*Paint, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "currentcolor") {
+		return &Paint{Kind: PaintKindCurrentColor}, nil
+	}
+	c, alpha, err := ParseColor(s)
+	if err != nil {
+		return nil, err
 	}
-	if s[0] == '#' && len(s) == 7 || len(s) == 4 {
-		// hex rgba
-		v, err := strconv.ParseUint(s[1:], 16, 32)
+	return &Paint{Kind: PaintKindRGB, Color: c, Alpha: alpha}, nil
+}
+
+// parsePaintURL parses a paint server reference with an optional fallback,
+// e.g. url(#grad) red
+func parsePaintURL(s string) (*Paint, error) {
+	id, rest, err := parseFuncIRI(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &Paint{Kind: PaintKindURL, Ref: id}
+	if rest = strings.TrimSpace(rest); len(rest) > 0 {
+		if isFuncIRI(rest) {
+			return nil, fmt.Errorf("invalid fallback")
+		}
+		p.Fallback, err = ParsePaint(rest)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid fallback: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// parseFuncIRI parses url(#id) reference at the beginning of s, returns the
+// id and the remaining content
+func parseFuncIRI(s string) (id string, rest string, err error) {
+	s = strings.TrimSpace(s)
+	if !isFuncIRI(s) {
+		return "", "", fmt.Errorf("url(#id) reference expected")
+	}
+	cp := strings.IndexByte(s, ')')
+	if cp < 0 {
+		return "", "", fmt.Errorf("missing closing parenthesis in url()")
+	}
+	iri := strings.TrimSpace(s[4:cp])
+	if len(iri) >= 2 && (iri[0] == '"' || iri[0] == '\'') && iri[len(iri)-1] == iri[0] {
+		iri = strings.TrimSpace(iri[1 : len(iri)-1])
+	}
+	id = hrefID(iri)
+	if id == "" {
+		return "", "", fmt.Errorf("unsupported url() reference '%s', #id expected", iri)
+	}
+	return id, s[cp+1:], nil
+}
+
+// isFuncIRI reports whether s starts with url( function, which is case
+// insensitive as all CSS functions are
+func isFuncIRI(s string) bool {
+	return len(s) >= 4 && strings.EqualFold(s[:4], "url(")
+}
+
+// ParseColor parses a color specified with CSS color syntax: a hex notation,
+// a color keyword, or rgb(), rgba(), hsl() and hsla() functions. Alpha is nil
+// when the specification does not include an alpha channel.
+func ParseColor(s string) (c RGB, alpha *float64, err error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		err = fmt.Errorf("empty specs")
+		return
+	}
+
+	if s[0] == '#' {
+		return parseHexColor(s[1:])
+	}
+
+	ls := strings.ToLower(s)
+	if ls == "transparent" {
+		a := 0.0
+		return RGB{}, &a, nil
+	}
+	if nc, ok := namedColors[ls]; ok {
+		return nc, nil, nil
+	}
+
+	op := strings.IndexByte(ls, '(')
+	if op < 0 || ls[len(ls)-1] != ')' {
+		err = fmt.Errorf("unsupported specs")
+		return
+	}
+	fn := strings.TrimSpace(ls[:op])
+	if fn == "url" {
+		err = fmt.Errorf("url() reference is not a color")
+		return
+	}
+	if cssColorFunctions[fn] {
+		err = fmt.Errorf("%w: color function '%s'", errUnsupported, fn)
+		return
+	}
+	args, a, err := splitColorArgs(ls[op+1 : len(ls)-1])
+	if err != nil {
+		return
+	}
+	if a != "" {
+		var av float64
+		av, err = parseAlpha(a)
+		if err != nil {
+			return
+		}
+		alpha = &av
+	}
+
+	switch fn {
+	case "rgb", "rgba":
+		var v [3]float64
+		for i, arg := range args {
+			if strings.HasSuffix(arg, "%") {
+				v[i], err = strconv.ParseFloat(arg[:len(arg)-1], 64)
+				v[i] = v[i] * 255 / 100
+			} else {
+				v[i], err = strconv.ParseFloat(arg, 64)
+			}
+			if err != nil {
+				return
+			}
+		}
+		c = RGB{channel(v[0]), channel(v[1]), channel(v[2])}
+	case "hsl", "hsla":
+		var h, sat, l float64
+		h, err = parseAngle(args[0])
+		if err != nil {
+			return
+		}
+		sat, err = strconv.ParseFloat(strings.TrimSuffix(args[1], "%"), 64)
+		if err != nil {
+			return
+		}
+		l, err = strconv.ParseFloat(strings.TrimSuffix(args[2], "%"), 64)
+		if err != nil {
+			return
+		}
+		c = hslToRGB(h, sat/100, l/100)
+	default:
+		err = fmt.Errorf("unsupported color function '%s'", fn)
+	}
+	return
+}
+
+// cssColorFunctions lists the functions of CSS Color 4 and 5 that are not
+// supported by ParseColor, and custom property references
+var cssColorFunctions = map[string]bool{
+	"hwb": true, "lab": true, "lch": true, "oklab": true, "oklch": true,
+	"color": true, "color-mix": true, "light-dark": true, "var": true,
+}
+
+func parseHexColor(s string) (c RGB, alpha *float64, err error) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return
+	}
+	switch len(s) {
+	case 3:
+		v = v<<4 | 0xf
+		fallthrough
+	case 4:
+		c.R = uint8((v>>12)&0xf) * 0x11
+		c.G = uint8((v>>8)&0xf) * 0x11
+		c.B = uint8((v>>4)&0xf) * 0x11
+		if len(s) == 4 {
+			a := float64(uint8(v&0xf)*0x11) / 255
+			alpha = &a
+		}
+	case 6:
+		v = v<<8 | 0xff
+		fallthrough
+	case 8:
+		c.R = uint8((v >> 24) & 0xff)
+		c.G = uint8((v >> 16) & 0xff)
+		c.B = uint8((v >> 8) & 0xff)
+		if len(s) == 8 {
+			a := float64(uint8(v&0xff)) / 255
+			alpha = &a
+		}
+	default:
+		err = fmt.Errorf("invalid hex color")
+	}
+	return
+}
+
+// splitColorArgs splits arguments of a color function, both the legacy comma
+// separated syntax and the space separated syntax with optional '/ alpha'
+// are supported
+func splitColorArgs(s string) (args []string, alpha string, err error) {
+	if strings.IndexByte(s, ',') >= 0 {
+		args = strings.Split(s, ",")
+		for i := range args {
+			args[i] = strings.TrimSpace(args[i])
 		}
-		p := &Paint{Kind: PaintKindRGB}
-		if len(s) == 7 {
-			p.Color.R = uint8((v >> 16) & 0xff)
-			p.Color.G = uint8((v >> 8) & 0xff)
-			p.Color.B = uint8(v & 0xff)
-		} else {
-			p.Color.R = uint8((v>>8)&0xf) * 0x11
-			p.Color.G = uint8((v>>4)&0xf) * 0x11
-			p.Color.B = uint8(v&0xf) * 0x11
+		if len(args) == 4 {
+			alpha = args[3]
+			args = args[:3]
+		}
+	} else {
+		if sl := strings.IndexByte(s, '/'); sl >= 0 {
+			alpha = strings.TrimSpace(s[sl+1:])
+			s = s[:sl]
+			if alpha == "" {
+				err = fmt.Errorf("missing alpha value")
+				return
+			}
+		}
+		args = strings.Fields(s)
+	}
+	if len(args) != 3 {
+		err = fmt.Errorf("invalid number of arguments")
+	}
+	return
+}
+
+func parseAlpha(s string) (float64, error) {
+	pct := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, err
+	}
+	if pct {
+		v /= 100
+	}
+	return math.Max(0, math.Min(1, v)), nil
+}
+
+// parseAngle parses CSS <angle> and returns its value in degrees, units are
+// optional
+func parseAngle(s string) (float64, error) {
+	scale := 1.0
+	for _, u := range []struct {
+		suffix string
+		scale  float64
+	}{{"deg", 1}, {"grad", 0.9}, {"rad", 180 / math.Pi}, {"turn", 360}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s = s[:len(s)-len(u.suffix)]
+			scale = u.scale
+			break
 		}
-		return p, nil
 	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v * scale, err
+}
+
+// channel converts a color component value to 0..255 range
+func channel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// hslToRGB converts hue in degrees, saturation and lightness in 0..1 range
+// into sRGB
+func hslToRGB(h, s, l float64) RGB {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = math.Max(0, math.Min(1, s))
+	l = math.Max(0, math.Min(1, l))
 
-	return nil, fmt.Errorf("unsupported specs")
+	f := func(n float64) uint8 {
+		k := math.Mod(n+h/30, 12)
+		a := s * math.Min(l, 1-l)
+		return channel(255 * (l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))))
+	}
+	return RGB{f(0), f(8), f(4)}
 }
 
+// ParseOpacity parses an opacity value specified as a number or as a
+// percentage
 func ParseOpacity(s string) (*float64, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return nil, fmt.Errorf("empty specs")
+	}
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		if err != nil {
+			return nil, err
+		}
+		v /= 100
+		return &v, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ParseNumber parses a plain SVG <number> value
+func ParseNumber(s string) (*float64, error) {
 	if len(s) == 0 {
 		return nil, fmt.Errorf("empty specs")
 	}
@@ -44,3 +332,43 @@ func ParseOpacity(s string) (*float64, error) {
 	}
 	return &v, nil
 }
+
+// ParseDashArray parses the content of stroke-dasharray property
+func ParseDashArray(s string) (*DashArray, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return nil, fmt.Errorf("empty specs")
+	}
+	da := DashArray{}
+	if s == "none" {
+		return &da, nil
+	}
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	}) {
+		v, _, err := Length(f).AsNumeric()
+		if err != nil {
+			return nil, err
+		}
+		if v < 0 {
+			return nil, fmt.Errorf("negative dash length")
+		}
+		da = append(da, Length(f))
+	}
+	return &da, nil
+}
+
+// parseOffset parses gradient stop offset, specified either as a number or a
+// percentage, and clamps it to [0..1] range
+func parseOffset(s string) (float64, error) {
+	v, u, err := parseLengthOrPercentage(strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	if u == UnitPercent {
+		v /= 100
+	} else if u != UnitNone {
+		return 0, fmt.Errorf("unexpected units")
+	}
+	return math.Max(0, math.Min(1, v)), nil
+}
@@ -20,6 +20,10 @@ func tokenizePath(s string) ([]token, error) {
 		return c >= '0' && c <= '9'
 	}
 
+	// arc tracks the arguments of arc commands, where the flags are single
+	// digits that do not require separators, e.g. a1 1 0 0110 10
+	arc, argIndex := false, 0
+
 	for cur < last {
 		if s[cur] <= ' ' || s[cur] == ',' {
 			cur++
@@ -27,6 +31,16 @@ func tokenizePath(s string) ([]token, error) {
 		}
 		if (s[cur] >= 'a' && s[cur] <= 'z') || (s[cur] >= 'A' && s[cur] <= 'Z') {
 			ret = append(ret, token{offset: cur, cmd: s[cur]})
+			arc, argIndex = s[cur] == 'a' || s[cur] == 'A', 0
+			cur++
+			continue
+		}
+		if arc && (argIndex%7 == 3 || argIndex%7 == 4) {
+			if s[cur] != '0' && s[cur] != '1' {
+				return nil, &PathError{Offset: cur, Msg: "invalid arc flag"}
+			}
+			ret = append(ret, token{offset: cur, cmd: '#', num: float64(s[cur] - '0')})
+			argIndex++
 			cur++
 			continue
 		}
@@ -53,13 +67,14 @@ func tokenizePath(s string) ([]token, error) {
 			}
 		}
 		if cur == start {
-			return nil, fmt.Errorf("invalid content at %d", cur)
+			return nil, &PathError{Offset: cur, Msg: "invalid content"}
 		}
 		v, err := strconv.ParseFloat(s[start:cur], 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid number at %d, %w", cur, err)
+			return nil, &PathError{Offset: start, Msg: "invalid number", Err: err}
 		}
 		ret = append(ret, token{offset: start, cmd: '#', num: v})
+		argIndex++
 	}
 	return ret, nil
 }
@@ -83,7 +98,7 @@ func ParsePath(s string) (*PathData, error) {
 	curToken, lastToken := 0, len(tokens)
 	for curToken < lastToken {
 		if tokens[curToken].cmd == '#' {
-			return nil, fmt.Errorf("unexpected number at %d", tokens[curToken].offset)
+			return nil, &PathError{Offset: tokens[curToken].offset, Msg: "unexpected number"}
 		}
 		cmd := tokens[curToken].cmd
 		offset := tokens[curToken].offset
@@ -108,7 +123,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 'm':
 			if n < 2 || n%2 != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			pt.X = values[0]
 			pt.Y = values[1]
@@ -134,14 +149,14 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 'z':
 			if n != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			last = first
 			pd.Close()
 
 		case 'l':
 			if n < 2 || n%2 != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			for len(values) >= 2 {
 				pt.X = values[0]
@@ -157,7 +172,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 'h':
 			if n == 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			for len(values) >= 1 {
 				pt.X = values[0]
@@ -172,7 +187,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 'v':
 			if n == 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			for len(values) >= 1 {
 				pt.X = last.X
@@ -187,7 +202,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 'c':
 			if n < 6 || n%6 != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			for len(values) >= 6 {
 				cpt[0].X = values[0]
@@ -211,7 +226,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 's':
 			if n < 4 || n%4 != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			if prevlccmd != 's' && prevlccmd != 'c' {
 				cpt[1] = last
@@ -236,7 +251,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 'q':
 			if n < 4 || n%4 != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			for len(values) >= 4 {
 				cpt[0].X = values[0]
@@ -257,7 +272,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 't':
 			if n < 2 || n%2 != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			if prevlccmd != 't' && prevlccmd != 'q' {
 				cpt[1] = last
@@ -279,7 +294,7 @@ func ParsePath(s string) (*PathData, error) {
 
 		case 'a':
 			if n < 7 || n%7 != 0 {
-				return nil, fmt.Errorf("invalid num arguments in '%c' command at %d", cmd, offset)
+				return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid num arguments in '%c' command", cmd)}
 			}
 			for len(values) >= 7 {
 				r := Vertex{values[0], values[1]}
@@ -294,7 +309,7 @@ func ParsePath(s string) (*PathData, error) {
 				last = pt
 			}
 		default:
-			return nil, fmt.Errorf("invalid path command '%c' at %d", cmd, offset)
+			return nil, &PathError{Offset: offset, Msg: fmt.Sprintf("invalid path command '%c'", cmd)}
 		}
 	}
 	return pd, nil
@@ -393,6 +408,12 @@ func (pd *PathData) Arc(lastX, lastY, rx, ry, axisRotation float64, largeArc, sw
 	n_segs := math.Round(0.5 + math.Abs(th_arc/(math.Pi*0.5+0.001)))
 
 	for i := 0; i < int(n_segs); i++ {
+		if i > 0 {
+			if pd.arcs == nil {
+				pd.arcs = map[int]bool{}
+			}
+			pd.arcs[len(pd.Commands)] = true
+		}
 		pd.ArcSegment(xc, yc,
 			th0+float64(i)*th_arc/n_segs,
 			th0+float64(i+1)*th_arc/n_segs,
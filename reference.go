@@ -0,0 +1,45 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reference implements values of properties that refer to another element
+// of the document with url(#id), such as clip-path. An empty ID corresponds
+// to 'none'.
+type Reference struct {
+	ID string
+}
+
+// ParseReference parses url(#id) reference or 'none'
+func ParseReference(s string) (*Reference, error) {
+	s = strings.TrimSpace(s)
+	if s == "none" {
+		return &Reference{}, nil
+	}
+	id, rest, err := parseFuncIRI(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(rest)) > 0 {
+		return nil, fmt.Errorf("unexpected content after url reference")
+	}
+	return &Reference{ID: id}, nil
+}
+
+func (r *Reference) String() string {
+	if r.ID == "" {
+		return "none"
+	}
+	return "url(#" + r.ID + ")"
+}
+
+// referenced returns the element referenced by r, or nil if r is nil, set
+// to 'none', or can not be resolved within the document
+func (svg *Svg) referenced(r *Reference) Item {
+	if r == nil || r.ID == "" {
+		return nil
+	}
+	return svg.ByID(r.ID)
+}
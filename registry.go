@@ -0,0 +1,85 @@
+package svg
+
+// container is implemented by items that have child items
+type container interface {
+	children() []Item
+}
+
+// Walk traverses the tree rooted at it in document order, calling fn for
+// each item, including it. Children of an item are skipped if fn returns
+// false.
+func Walk(it Item, fn func(it Item) bool) {
+	if it == nil || !fn(it) {
+		return
+	}
+	if c, ok := it.(container); ok {
+		for _, ch := range c.children() {
+			Walk(ch, fn)
+		}
+	}
+}
+
+// presenter is implemented by items that have presentation properties
+type presenter interface {
+	presentation() *Presentation
+}
+
+// ByID returns the element with the specified id, or nil if the document
+// does not contain such element. When several elements share the same id,
+// the first one in document order is returned.
+func (svg *Svg) ByID(id string) Item {
+	if svg.ids == nil {
+		svg.Reindex()
+	}
+	return svg.ids[id]
+}
+
+// Reindex rebuilds the registry of element ids that is used by ByID. It is
+// called by Parse and Resolve, and must be called again after the document
+// tree is modified. Returns the ids that are shared by more than one element.
+func (svg *Svg) Reindex() (duplicates []string) {
+	svg.ids = map[string]Item{}
+	svg.duplicates = nil
+	reported := map[string]bool{}
+	Walk(svg, func(it Item) bool {
+		id := it.ID()
+		if id == "" {
+			return true
+		}
+		if _, exists := svg.ids[id]; !exists {
+			svg.ids[id] = it
+		} else if !reported[id] {
+			reported[id] = true
+			svg.duplicates = append(svg.duplicates, id)
+		}
+		return true
+	})
+	return svg.duplicates
+}
+
+// DuplicateIDs returns the ids that are shared by more than one element, as
+// of the last Reindex
+func (svg *Svg) DuplicateIDs() []string {
+	if svg.ids == nil {
+		svg.Reindex()
+	}
+	return svg.duplicates
+}
+
+// Resolve rebuilds the id registry and binds references between elements,
+// such as url(#id) paints, to the referenced elements. It is called by Parse,
+// and must be called again after the document tree is modified.
+func (svg *Svg) Resolve() {
+	svg.Reindex()
+	Walk(svg, func(it Item) bool {
+		if p, ok := it.(presenter); ok {
+			pr := p.presentation()
+			for _, paint := range []*Paint{pr.Fill, pr.Stroke} {
+				if paint != nil {
+					paint.bind(svg)
+				}
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,83 @@
+package svg
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// ParseReader reads and parses a document from r, gzip compressed content
+// such as that of .svgz files is decompressed transparently
+func ParseReader(r io.Reader, opts ...ParseOption) (*Svg, error) {
+	s, err := readDocument(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(s, opts...)
+}
+
+// ParseFile reads and parses the document stored in a file, either plain or
+// gzip compressed
+func ParseFile(path string, opts ...ParseOption) (*Svg, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseFile(f, opts...)
+}
+
+// ParseFS reads and parses the document stored in a file of fsys, such as
+// embed.FS, either plain or gzip compressed
+func ParseFS(fsys fs.FS, name string, opts ...ParseOption) (*Svg, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseFile(f, opts...)
+}
+
+// parseFile reads and parses an open file, its size is used to preallocate
+// the document
+func parseFile(f fs.File, opts ...ParseOption) (*Svg, error) {
+	size := 0
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		size = int(fi.Size())
+	}
+	s, err := readDocument(f, size)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(s, opts...)
+}
+
+// readDocument reads the content of r into a string without an intermediate
+// byte slice, the content is decompressed first if it starts with the gzip
+// header. The size hint is used to preallocate the string.
+func readDocument(r io.Reader, size int) (string, error) {
+	br := bufio.NewReader(r)
+	r = br
+	if magic, _ := br.Peek(2); isGzip(magic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return "", err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	sb := strings.Builder{}
+	sb.Grow(size)
+	if _, err := io.Copy(&sb, r); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// isGzip reports whether data starts with the gzip magic number
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
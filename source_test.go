@@ -0,0 +1,69 @@
+package svg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseSources(t *testing.T) {
+	plain, err := os.ReadFile("testdata/arrow-down.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Parse(string(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zbuf := bytes.Buffer{}
+	if err = Write(&zbuf, want, WithGzip(gzip.BestCompression)); err != nil {
+		t.Fatal(err)
+	}
+	if !isGzip(zbuf.Bytes()) {
+		t.Fatalf("WithGzip output is not compressed")
+	}
+	compressed := zbuf.Bytes()
+
+	dir := t.TempDir()
+	if err = os.WriteFile(filepath.Join(dir, "icon.svgz"), compressed, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"icons/plain.svg":  {Data: plain},
+		"icons/packed.svg": {Data: compressed},
+	}
+
+	check := func(name string, got *Svg, err error) {
+		t.Helper()
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: document mismatch", name)
+		}
+	}
+	got, err := ParseReader(bytes.NewReader(plain))
+	check("ParseReader plain", got, err)
+	got, err = ParseReader(bytes.NewReader(compressed))
+	check("ParseReader gzip", got, err)
+	got, err = ParseFile("testdata/arrow-down.svg")
+	check("ParseFile plain", got, err)
+	got, err = ParseFile(filepath.Join(dir, "icon.svgz"))
+	check("ParseFile gzip", got, err)
+	got, err = ParseFS(fsys, "icons/plain.svg")
+	check("ParseFS plain", got, err)
+	got, err = ParseFS(fsys, "icons/packed.svg")
+	check("ParseFS gzip", got, err)
+
+	if _, err = ParseFS(fsys, "icons/missing.svg"); err == nil {
+		t.Errorf("expected error for a missing file")
+	}
+	if _, err = ParseReader(strings.NewReader("\x1f\x8bnot really gzip")); err == nil {
+		t.Errorf("expected error for corrupted gzip content")
+	}
+}
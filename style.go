@@ -0,0 +1,33 @@
+package svg
+
+// Style implements SVG <style> element
+//
+// The style sheet content is kept verbatim, its rules are applied to the
+// presentation properties of matching elements while the document is
+// parsed.
+type Style struct {
+	item
+	Type    string
+	Media   string
+	Content string
+}
+
+func (s *Style) read(src sourcer) (err error) {
+	err = s.item.read(src)
+	if err != nil {
+		return
+	}
+	s.Type, _ = src.Attr("type")
+	s.Media, _ = src.Attr("media")
+	return src.ForEachChild(nil, func(text string) error {
+		s.Content += text
+		return nil
+	})
+}
+
+func (s *Style) write(tgt targeter) {
+	s.item.write(tgt)
+	tgt.Attr("type", s.Type)
+	tgt.Attr("media", s.Media)
+	tgt.Text(s.Content)
+}
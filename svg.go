@@ -2,6 +2,7 @@ package svg
 
 import (
 	"fmt"
+	"reflect"
 )
 
 type Item interface {
@@ -10,15 +11,82 @@ type Item interface {
 }
 
 type item struct {
-	id string
+	id    string
+	class string
+	Conditions
+
+	// ExtraAttrs holds the attributes that are not supported, such as
+	// data-*, aria-* and attributes of editor namespaces, in document order
+	ExtraAttrs []Attribute
+
+	// extraStyle holds declarations of the style attribute for properties
+	// that are not supported
+	extraStyle []declaration
+
+	// sources holds the origins of the property values that are specified
+	// with the style sheet or with the style attribute
+	sources map[string]propertySource
+
+	// hrefAttr holds the name of the attribute that the reference to
+	// another element is read from, href if empty
+	hrefAttr string
+}
+
+// Attribute is an XML attribute, with the value unescaped
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+func (it *item) base() *item {
+	return it
+}
+
+// ExtraAttr returns the value of an unsupported attribute
+func (it *item) ExtraAttr(name string) (v string, exists bool) {
+	for _, a := range it.ExtraAttrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetExtraAttr assigns the value of an unsupported attribute, the attribute
+// is appended if it does not exist
+func (it *item) SetExtraAttr(name, v string) {
+	for i := range it.ExtraAttrs {
+		if it.ExtraAttrs[i].Name == name {
+			it.ExtraAttrs[i].Value = v
+			return
+		}
+	}
+	it.ExtraAttrs = append(it.ExtraAttrs, Attribute{Name: name, Value: v})
+}
+
+// DeleteExtraAttr removes an unsupported attribute
+func (it *item) DeleteExtraAttr(name string) {
+	for i := range it.ExtraAttrs {
+		if it.ExtraAttrs[i].Name == name {
+			it.ExtraAttrs = append(it.ExtraAttrs[:i:i], it.ExtraAttrs[i+1:]...)
+			return
+		}
+	}
 }
 
 func (it *item) ID() string {
 	return it.id
 }
 
+// Class returns the content of the class attribute
+func (it *item) Class() string {
+	return it.class
+}
+
 func (it *item) read(src sourcer) (err error) {
 	it.id, _ = src.Attr("id")
+	it.class, _ = src.Attr("class")
+	it.Conditions.read(src)
 	return nil
 }
 
@@ -26,6 +94,76 @@ func (it *item) write(tgt targeter) {
 	if len(it.id) > 0 {
 		tgt.Attr("id", it.id)
 	}
+	if len(it.class) > 0 {
+		tgt.Attr("class", it.class)
+	}
+	tgt.PropertySources(it.sources)
+	it.Conditions.write(tgt)
+	for _, a := range it.ExtraAttrs {
+		tgt.ExtraAttr(a.Name, a.Value)
+	}
+	for _, d := range it.extraStyle {
+		tgt.StyleProperty(d)
+	}
+}
+
+// writeHref writes the reference to another element with the attribute that
+// it was read from
+func (it *item) writeHref(tgt targeter, v string) {
+	name := it.hrefAttr
+	if name == "" {
+		name = "href"
+	}
+	tgt.Attr(name, v)
+}
+
+// readItem reads it from src, the attributes and style declarations that
+// are not used by the reader are kept with the item, so that these can be
+// written back
+//
+// In lenient mode, the item is read again without the attribute that makes
+// it fail, the attributes are dropped one at a time. Only the warnings of the
+// last attempt are reported.
+func readItem(it reader, src sourcer) error {
+	v := reflect.ValueOf(it).Elem()
+	initial := reflect.New(v.Type()).Elem()
+	initial.Set(v)
+	for {
+		err := it.read(src)
+		if err == nil {
+			break
+		}
+		if !src.recover(err) {
+			src.flush()
+			return src.fail(err)
+		}
+		v.Set(initial)
+	}
+	src.flush()
+	if b, ok := it.(interface{ base() *item }); ok {
+		b.base().ExtraAttrs, b.base().extraStyle = src.unused()
+		b.base().sources = formattedSources(it, src.propertySources())
+	}
+	return nil
+}
+
+// formattedSources completes the property origins with the values formatted
+// the way these are written, so that the writer can tell whether the values
+// are changed
+func formattedSources(it writer, sources map[string]propertySource) map[string]propertySource {
+	if len(sources) == 0 {
+		return nil
+	}
+	pc := propertyCapture{}
+	it.write(pc)
+	ret := map[string]propertySource{}
+	for name, s := range sources {
+		if v, ok := pc[name]; ok {
+			s.value = v
+			ret[name] = s
+		}
+	}
+	return ret
 }
 
 type Node struct {
@@ -38,48 +176,113 @@ func (n *Node) read(src sourcer) error {
 	if err != nil {
 		return err
 	}
-	return src.ForEachChildNode(func(tag string, cs sourcer) error {
-		var it reader
-
-		switch tag {
-		case "g":
-			it = &Group{}
-		case "line":
-			it = &Line{}
-		case "rect":
-			it = &Rect{}
-		case "circle":
-			it = &Circle{}
-		case "ellipse":
-			it = &Ellipse{}
-		case "polyline":
-			it = &Polygon{}
-		case "polygon":
-			it = &Polygon{}
-		case "path":
-			it = &Path{}
-		case "text":
-			// todo: implement
-		}
+	return readChildren(src, &n.Items)
+}
 
-		if it != nil {
-			err := it.read(cs)
-			if err != nil {
-				return fmt.Errorf("in <%s>: %s", tag, err)
-			}
-			n.Items = append(n.Items, it)
+// readChildren reads child elements and comments, the elements that are not
+// supported are kept as Unknown items
+func readChildren(src sourcer, items *[]Item) error {
+	return src.ForEachContent(func(tag string, cs sourcer) error {
+		it := newItem(tag)
+		if err := readItem(it, cs); err != nil {
+			return err
 		}
+		*items = append(*items, it)
+		return nil
+	}, nil, func(text string) error {
+		*items = append(*items, &Comment{Text: text})
 		return nil
 	})
 }
 
+// newItem creates the item that reads an element with the given tag, Unknown
+// for the elements that are not supported
+func newItem(tag string) reader {
+	switch tag {
+	case "g":
+		return &Group{}
+	case "defs":
+		return &Defs{}
+	case "line":
+		return &Line{}
+	case "rect":
+		return &Rect{}
+	case "circle":
+		return &Circle{}
+	case "ellipse":
+		return &Ellipse{}
+	case "polyline":
+		return &Polyline{}
+	case "polygon":
+		return &Polygon{}
+	case "path":
+		return &Path{}
+	case "use":
+		return &Use{}
+	case "symbol":
+		return &Symbol{}
+	case "clipPath":
+		return &ClipPath{}
+	case "mask":
+		return &Mask{}
+	case "pattern":
+		return &Pattern{}
+	case "marker":
+		return &Marker{}
+	case "image":
+		return &Image{}
+	case "title":
+		return &Title{}
+	case "desc":
+		return &Desc{}
+	case "metadata":
+		return &Metadata{}
+	case "linearGradient":
+		return &LinearGradient{}
+	case "radialGradient":
+		return &RadialGradient{}
+	case "style":
+		return &Style{}
+	case "text":
+		return &Text{}
+	case "svg":
+		return &Svg{}
+	case "filter":
+		return &Filter{}
+	case "a":
+		return &Anchor{}
+	case "switch":
+		return &Switch{}
+	default:
+		return &Unknown{Tag: tag}
+	}
+}
+
+func (n *Node) node() *Node {
+	return n
+}
+
+func (n *Node) children() []Item {
+	return n.Items
+}
+
 func (n *Node) write(tgt targeter) {
 	n.item.write(tgt)
-	for _, it := range n.Items {
+	n.writeItems(tgt)
+}
+
+func (n *Node) writeItems(tgt targeter) {
+	writeChildren(tgt, n.Items)
+}
+
+func writeChildren(tgt targeter, items []Item) {
+	for _, it := range items {
 		tag := ""
 		switch it.(type) {
 		case *Group:
 			tag = "g"
+		case *Defs:
+			tag = "defs"
 		case *Line:
 			tag = "line"
 		case *Rect:
@@ -94,8 +297,49 @@ func (n *Node) write(tgt targeter) {
 			tag = "polygon"
 		case *Path:
 			tag = "path"
+		case *Use:
+			tag = "use"
+		case *Symbol:
+			tag = "symbol"
+		case *ClipPath:
+			tag = "clipPath"
+		case *Mask:
+			tag = "mask"
+		case *Pattern:
+			tag = "pattern"
+		case *Marker:
+			tag = "marker"
+		case *Image:
+			tag = "image"
+		case *Title:
+			tag = "title"
+		case *Desc:
+			tag = "desc"
+		case *Metadata:
+			tag = "metadata"
+		case *LinearGradient:
+			tag = "linearGradient"
+		case *RadialGradient:
+			tag = "radialGradient"
+		case *Style:
+			tag = "style"
+		case *Text:
+			tag = "text"
+		case *Svg:
+			tag = "svg"
+		case *Filter:
+			tag = "filter"
+		case *Anchor:
+			tag = "a"
+		case *Switch:
+			tag = "switch"
+		case *Unknown:
+			tag = it.(*Unknown).Tag
+		case *Comment:
+			it.write(tgt)
+			continue
 		default:
-			panic("inknown element tag")
+			panic("unknown element tag")
 		}
 		tgt.Child(tag, func(t targeter) {
 			it.write(t)
@@ -105,16 +349,23 @@ func (n *Node) write(tgt targeter) {
 
 type Shape struct {
 	item
-	Fill           *Paint
-	FillRule       *FillRule
-	FillOpacity    *float64
-	Stroke         *Paint
-	StrokeWidth    Length
-	StrokeOpacity  *float64
-	StrokeLineCap  *LineCap
-	StrokeLineJoin *LineJoin
-	Opacity        *float64
-	Transform      *Transform
+	Presentation
+	Transform *Transform
+}
+
+// shapeNode is a shape with child elements, such as <title>, the children
+// of <text> are kept with its content instead
+type shapeNode struct {
+	Shape
+	Items []Item
+}
+
+func (s *shapeNode) shape() *shapeNode {
+	return s
+}
+
+func (s *shapeNode) children() []Item {
+	return s.Items
 }
 
 func (s *Shape) read(src sourcer) (err error) {
@@ -122,100 +373,58 @@ func (s *Shape) read(src sourcer) (err error) {
 	if err != nil {
 		return
 	}
-
-	if v, exists := src.Attr("fill"); exists {
-		s.Fill, err = ParsePaint(v)
-		if err != nil {
-			return fmt.Errorf("invalid fill: %w", err)
-		}
-	}
-	if v, exists := src.Attr("fill-rule"); exists {
-		r := FillRuleInherit
-		err = r.UnmarshalText([]byte(v))
-		if err != nil {
-			return fmt.Errorf("invalid fill-rule: %w", err)
-		}
-		s.FillRule = &r
-	}
-
-	if v, exists := src.Attr("fill-opacity"); exists {
-		s.FillOpacity, err = ParseOpacity(v)
-		if err != nil {
-			return fmt.Errorf("invalid fill-opacity: %w", err)
-		}
-	}
-
-	//if v, exists := src.Attr("stroke"); exists {
-	//	todo
-	//}
-
-	if v, exists := src.Attr("stroke-width"); exists {
-		s.StrokeWidth = Length(v)
-	}
-
-	if v, exists := src.Attr("stroke-opacity"); exists {
-		s.StrokeOpacity, err = ParseOpacity(v)
-		if err != nil {
-			return fmt.Errorf("invalid stroke-opacity: %w", err)
-		}
-	}
-
-	if v, exists := src.Attr("stroke-line-cap"); exists {
-		r := LineCapButt
-		err = r.UnmarshalText([]byte(v))
-		if err != nil {
-			return fmt.Errorf("invalid stroke-line-cap: %w", err)
-		}
-		s.StrokeLineCap = &r
-	}
-
-	if v, exists := src.Attr("stroke-line-join"); exists {
-		r := LineJoinMiter
-		err = r.UnmarshalText([]byte(v))
-		if err != nil {
-			return fmt.Errorf("invalid stroke-line-join: %w", err)
-		}
-		s.StrokeLineJoin = &r
+	err = s.Presentation.read(src)
+	if err != nil {
+		return
 	}
-
-	if v, exists := src.Attr("opacity"); exists {
-		s.Opacity, err = ParseOpacity(v)
+	if v, exists := src.Attr("transform"); exists {
+		s.Transform, err = parseTransformAttr(v)
 		if err != nil {
-			return fmt.Errorf("invalid opacity: %w", err)
+			return fmt.Errorf("invalid transform: %w", err)
 		}
 	}
-
 	return
 }
 
 func (s *Shape) write(tgt targeter) {
 	s.item.write(tgt)
+	s.Presentation.write(tgt)
+	if s.Transform != nil {
+		tgt.Attr("transform", s.Transform.String())
+	}
 }
 
 type Group struct {
 	Node
-	Opacity   *float64
+	Presentation
 	Transform *Transform
 }
 
 func (g *Group) read(src sourcer) (err error) {
-	err = g.Node.read(src)
+	err = g.item.read(src)
 	if err != nil {
 		return
 	}
-	if v, exists := src.Attr("opacity"); exists {
-		g.Opacity, err = ParseOpacity(v)
+	err = g.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	if v, exists := src.Attr("transform"); exists {
+		g.Transform, err = parseTransformAttr(v)
 		if err != nil {
-			return fmt.Errorf("invalid opacity: %w", err)
+			return fmt.Errorf("invalid transform: %w", err)
 		}
 	}
-	return
+	return readChildren(src, &g.Items)
 }
 
 func (g *Group) write(tgt targeter) {
-	g.Node.write(tgt)
-
-	// todo: Opacity field
+	g.item.write(tgt)
+	g.Presentation.write(tgt)
+	if g.Transform != nil {
+		tgt.Attr("transform", g.Transform.String())
+	}
+	g.writeItems(tgt)
 }
 
 type Defs struct {
@@ -223,31 +432,52 @@ type Defs struct {
 	Transform *Transform
 }
 
-// Svg implements SVG <svg> element
+func (d *Defs) read(src sourcer) (err error) {
+	err = d.item.read(src)
+	if err != nil {
+		return
+	}
+	if v, exists := src.Attr("transform"); exists {
+		d.Transform, err = parseTransformAttr(v)
+		if err != nil {
+			return fmt.Errorf("invalid transform: %w", err)
+		}
+	}
+	return readChildren(src, &d.Items)
+}
+
+func (d *Defs) write(tgt targeter) {
+	d.item.write(tgt)
+	if d.Transform != nil {
+		tgt.Attr("transform", d.Transform.String())
+	}
+	d.writeItems(tgt)
+}
+
+// Svg implements SVG <svg> element, either the root of a document or a
+// nested viewport
 type Svg struct {
 	Group
-	ViewBox ViewBox
-	X       Coordinate
-	Y       Coordinate
-	Width   Length
-	Height  Length
+	ViewBox             *ViewBoxValue
+	PreserveAspectRatio *PreserveAspectRatio
+	X                   Coordinate
+	Y                   Coordinate
+	Width               Length
+	Height              Length
+
+	ids        map[string]Item
+	duplicates []string
+
+	// markup that precedes and follows the root element of a document, such
+	// as the XML declaration and comments, kept as is
+	prolog, epilog string
 }
 
 func (svg *Svg) read(src sourcer) (err error) {
-	if s, ok := src.Attr("viewBox"); ok {
-		svg.ViewBox = ViewBox(s)
-	}
-	if s, ok := src.Attr("x"); ok {
-		svg.X = Coordinate(s)
-	}
-	if s, ok := src.Attr("y"); ok {
-		svg.Y = Coordinate(s)
-	}
-	if s, ok := src.Attr("width"); ok {
-		svg.Width = Length(s)
-	}
-	if s, ok := src.Attr("height"); ok {
-		svg.Height = Length(s)
+	err = readViewport(src, &svg.ViewBox, &svg.PreserveAspectRatio,
+		&svg.X, &svg.Y, &svg.Width, &svg.Height)
+	if err != nil {
+		return err
 	}
 	err = svg.Group.read(src)
 	if err != nil {
@@ -257,16 +487,19 @@ func (svg *Svg) read(src sourcer) (err error) {
 }
 
 func (svg *Svg) write(tgt targeter) {
-	tgt.Attr("viewBox", string(svg.ViewBox))
-	tgt.Attr("x", string(svg.X))
-	tgt.Attr("y", string(svg.Y))
-	tgt.Attr("width", string(svg.Width))
-	tgt.Attr("height", string(svg.Height))
+	writeViewport(tgt, svg.ViewBox, svg.PreserveAspectRatio, svg.X, svg.Y, svg.Width, svg.Height)
 	svg.Group.write(tgt)
 }
 
+// ViewportTransform returns the transform from the user coordinate system
+// established by the viewBox into the viewport with the size of width by
+// height
+func (svg *Svg) ViewportTransform(width, height float64) *Transform {
+	return ViewportTransform(svg.ViewBox, svg.PreserveAspectRatio, 0, 0, width, height)
+}
+
 type Line struct {
-	Shape
+	shapeNode
 	X1 Coordinate
 	Y1 Coordinate
 	X2 Coordinate
@@ -290,7 +523,7 @@ func (l *Line) read(src sourcer) (err error) {
 	if s, ok := src.Attr("y2"); ok {
 		l.Y2 = Coordinate(s)
 	}
-	return
+	return readChildren(src, &l.Items)
 }
 
 func (l *Line) write(tgt targeter) {
@@ -299,10 +532,11 @@ func (l *Line) write(tgt targeter) {
 	tgt.Attr("y1", string(l.Y1))
 	tgt.Attr("x2", string(l.X2))
 	tgt.Attr("y2", string(l.Y2))
+	writeChildren(tgt, l.Items)
 }
 
 type Rect struct {
-	Shape
+	shapeNode
 	X      Coordinate
 	Y      Coordinate
 	Width  Length
@@ -334,7 +568,7 @@ func (r *Rect) read(src sourcer) (err error) {
 	if s, ok := src.Attr("ry"); ok {
 		r.Ry = Length(s)
 	}
-	return
+	return readChildren(src, &r.Items)
 }
 
 func (r *Rect) write(tgt targeter) {
@@ -345,10 +579,11 @@ func (r *Rect) write(tgt targeter) {
 	tgt.Attr("height", string(r.Height))
 	tgt.Attr("rx", string(r.Rx))
 	tgt.Attr("ry", string(r.Ry))
+	writeChildren(tgt, r.Items)
 }
 
 type Circle struct {
-	Shape
+	shapeNode
 	Cx     Coordinate
 	Cy     Coordinate
 	Radius Length
@@ -368,7 +603,7 @@ func (c *Circle) read(src sourcer) (err error) {
 	if s, ok := src.Attr("r"); ok {
 		c.Radius = Length(s)
 	}
-	return
+	return readChildren(src, &c.Items)
 }
 
 func (c *Circle) write(tgt targeter) {
@@ -376,10 +611,11 @@ func (c *Circle) write(tgt targeter) {
 	tgt.Attr("cx", string(c.Cx))
 	tgt.Attr("cy", string(c.Cy))
 	tgt.Attr("r", string(c.Radius))
+	writeChildren(tgt, c.Items)
 }
 
 type Ellipse struct {
-	Shape
+	shapeNode
 	Cx Coordinate
 	Cy Coordinate
 	Rx Length
@@ -403,7 +639,7 @@ func (e *Ellipse) read(src sourcer) (err error) {
 	if s, ok := src.Attr("ry"); ok {
 		e.Ry = Length(s)
 	}
-	return
+	return readChildren(src, &e.Items)
 }
 
 func (e *Ellipse) write(tgt targeter) {
@@ -412,10 +648,11 @@ func (e *Ellipse) write(tgt targeter) {
 	tgt.Attr("cy", string(e.Cy))
 	tgt.Attr("rx", string(e.Rx))
 	tgt.Attr("ry", string(e.Ry))
+	writeChildren(tgt, e.Items)
 }
 
 type Polyline struct {
-	Shape
+	shapeNode
 	Points string
 }
 
@@ -427,16 +664,17 @@ func (p *Polyline) read(src sourcer) (err error) {
 	if s, ok := src.Attr("points"); ok {
 		p.Points = s
 	}
-	return
+	return readChildren(src, &p.Items)
 }
 
 func (p *Polyline) write(tgt targeter) {
 	p.Shape.write(tgt)
 	tgt.Attr("points", p.Points)
+	writeChildren(tgt, p.Items)
 }
 
 type Polygon struct {
-	Shape
+	shapeNode
 	Points string
 }
 
@@ -448,16 +686,17 @@ func (p *Polygon) read(src sourcer) (err error) {
 	if s, ok := src.Attr("points"); ok {
 		p.Points = s
 	}
-	return
+	return readChildren(src, &p.Items)
 }
 
 func (p *Polygon) write(tgt targeter) {
 	p.Shape.write(tgt)
 	tgt.Attr("points", p.Points)
+	writeChildren(tgt, p.Items)
 }
 
 type Path struct {
-	Shape
+	shapeNode
 	D string
 }
 
@@ -467,12 +706,17 @@ func (p *Path) read(src sourcer) (err error) {
 		return
 	}
 	if s, ok := src.Attr("d"); ok {
+		// path data is kept as is, errors are only reported as warnings
+		if _, e := ParsePath(s); e != nil {
+			src.warnAttr("d", fmt.Errorf("invalid d: %w", e))
+		}
 		p.D = s
 	}
-	return
+	return readChildren(src, &p.Items)
 }
 
 func (p *Path) write(tgt targeter) {
 	p.Shape.write(tgt)
 	tgt.Attr("d", p.D)
+	writeChildren(tgt, p.Items)
 }
@@ -0,0 +1,439 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TextContent is implemented by the content of text elements: character
+// data, and child elements such as <tspan> and <textPath>
+type TextContent interface {
+	writer
+}
+
+// CharData is character data within a text element, kept verbatim,
+// including whitespace
+type CharData string
+
+func (c CharData) write(tgt targeter) {
+	tgt.Text(string(c))
+}
+
+// LengthList implements SVG list of <length> values, such as the x, y, dx
+// and dy attributes of text elements
+type LengthList []Length
+
+// ParseLengthList parses a whitespace and/or comma separated list of
+// lengths
+func ParseLengthList(s string) (LengthList, error) {
+	ll := LengthList{}
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	}) {
+		if _, _, err := Length(f).AsNumeric(); err != nil {
+			return nil, err
+		}
+		ll = append(ll, Length(f))
+	}
+	return ll, nil
+}
+
+func (ll LengthList) String() string {
+	ss := make([]string, len(ll))
+	for i, l := range ll {
+		ss[i] = string(l)
+	}
+	return strings.Join(ss, " ")
+}
+
+// NumberList implements SVG list of <number> values, such as the rotate
+// attribute of text elements
+type NumberList []float64
+
+// ParseNumberList parses a whitespace and/or comma separated list of
+// numbers
+func ParseNumberList(s string) (NumberList, error) {
+	vv, err := tokenizePoints(s)
+	if err != nil {
+		return nil, err
+	}
+	return NumberList(vv), nil
+}
+
+func (nl NumberList) String() string {
+	ss := make([]string, len(nl))
+	for i, v := range nl {
+		ss[i] = formatNumber(v)
+	}
+	return strings.Join(ss, " ")
+}
+
+// TextAnchor implements SVG text-anchor property value
+type TextAnchor int
+
+const (
+	TextAnchorStart = TextAnchor(iota)
+	TextAnchorMiddle
+	TextAnchorEnd
+)
+
+func (ta TextAnchor) String() string {
+	switch ta {
+	case TextAnchorStart:
+		return "start"
+	case TextAnchorMiddle:
+		return "middle"
+	case TextAnchorEnd:
+		return "end"
+	default:
+		return ""
+	}
+}
+
+func (ta *TextAnchor) UnmarshalText(text []byte) error {
+	s := string(text)
+	switch s {
+	case "start":
+		*ta = TextAnchorStart
+	case "middle":
+		*ta = TextAnchorMiddle
+	case "end":
+		*ta = TextAnchorEnd
+	default:
+		return errors.New("invalid text-anchor value")
+	}
+	return nil
+}
+
+// DominantBaseline implements SVG dominant-baseline property value
+type DominantBaseline int
+
+const (
+	DominantBaselineAuto = DominantBaseline(iota)
+	DominantBaselineTextBottom
+	DominantBaselineAlphabetic
+	DominantBaselineIdeographic
+	DominantBaselineMiddle
+	DominantBaselineCentral
+	DominantBaselineMathematical
+	DominantBaselineHanging
+	DominantBaselineTextTop
+
+	// SVG 1.1 values, these are still accepted by renderers
+	DominantBaselineUseScript
+	DominantBaselineNoChange
+	DominantBaselineResetSize
+	DominantBaselineTextAfterEdge
+	DominantBaselineTextBeforeEdge
+)
+
+var dominantBaselineNames = []string{
+	"auto",
+	"text-bottom",
+	"alphabetic",
+	"ideographic",
+	"middle",
+	"central",
+	"mathematical",
+	"hanging",
+	"text-top",
+	"use-script",
+	"no-change",
+	"reset-size",
+	"text-after-edge",
+	"text-before-edge",
+}
+
+func (db DominantBaseline) String() string {
+	if db < 0 || int(db) >= len(dominantBaselineNames) {
+		return ""
+	}
+	return dominantBaselineNames[db]
+}
+
+func (db *DominantBaseline) UnmarshalText(text []byte) error {
+	s := string(text)
+	for i, n := range dominantBaselineNames {
+		if s == n {
+			*db = DominantBaseline(i)
+			return nil
+		}
+	}
+	return errors.New("invalid dominant-baseline value")
+}
+
+// validFontSize checks font-size property value, which is either a keyword
+// or a length
+func validFontSize(s string) error {
+	switch s {
+	case "xx-small", "x-small", "small", "medium", "large", "x-large",
+		"xx-large", "xxx-large", "larger", "smaller":
+		return nil
+	}
+	if _, _, err := Length(s).AsNumeric(); err != nil {
+		return errors.New("invalid font-size value")
+	}
+	return nil
+}
+
+// validFontWeight checks font-weight property value, which is either a
+// keyword or a number in the range of [1..1000]
+func validFontWeight(s string) error {
+	switch s {
+	case "normal", "bold", "bolder", "lighter":
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 1 || v > 1000 {
+		return errors.New("invalid font-weight value")
+	}
+	return nil
+}
+
+// TextPositioning holds the attributes that position individual characters
+// of <text> and <tspan> elements
+type TextPositioning struct {
+	X      LengthList
+	Y      LengthList
+	Dx     LengthList
+	Dy     LengthList
+	Rotate NumberList
+}
+
+func (tp *TextPositioning) read(src sourcer) (err error) {
+	for _, a := range []struct {
+		name string
+		ll   *LengthList
+	}{
+		{"x", &tp.X},
+		{"y", &tp.Y},
+		{"dx", &tp.Dx},
+		{"dy", &tp.Dy},
+	} {
+		if s, ok := src.Attr(a.name); ok {
+			*a.ll, err = ParseLengthList(s)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", a.name, err)
+			}
+		}
+	}
+	if s, ok := src.Attr("rotate"); ok {
+		tp.Rotate, err = ParseNumberList(s)
+		if err != nil {
+			return fmt.Errorf("invalid rotate: %w", err)
+		}
+	}
+	return
+}
+
+func (tp *TextPositioning) write(tgt targeter) {
+	tgt.Attr("x", tp.X.String())
+	tgt.Attr("y", tp.Y.String())
+	tgt.Attr("dx", tp.Dx.String())
+	tgt.Attr("dy", tp.Dy.String())
+	tgt.Attr("rotate", tp.Rotate.String())
+}
+
+// readTextContent reads character data, comments and child elements of text
+// content, the elements that are not supported are kept as Unknown items
+func readTextContent(src sourcer, content *[]TextContent) error {
+	return src.ForEachContent(func(tag string, cs sourcer) error {
+		var it reader
+		switch tag {
+		case "tspan":
+			it = &TSpan{}
+		case "textPath":
+			it = &TextPath{}
+		case "title":
+			it = &Title{}
+		case "desc":
+			it = &Desc{}
+		default:
+			it = &Unknown{Tag: tag}
+		}
+		if err := readItem(it, cs); err != nil {
+			return err
+		}
+		*content = append(*content, it)
+		return nil
+	}, func(text string) error {
+		*content = append(*content, CharData(text))
+		return nil
+	}, func(text string) error {
+		*content = append(*content, &Comment{Text: text})
+		return nil
+	})
+}
+
+func writeTextContent(tgt targeter, content []TextContent) {
+	for _, c := range content {
+		switch c := c.(type) {
+		case CharData:
+			c.write(tgt)
+		case *TSpan:
+			tgt.Child("tspan", c.write)
+		case *TextPath:
+			tgt.Child("textPath", c.write)
+		case *Title:
+			tgt.Child("title", c.write)
+		case *Desc:
+			tgt.Child("desc", c.write)
+		case *Unknown:
+			tgt.Child(c.Tag, c.write)
+		case *Comment:
+			c.write(tgt)
+		default:
+			panic("unknown text content")
+		}
+	}
+}
+
+// textItems returns the elements within text content
+func textItems(content []TextContent) []Item {
+	ret := []Item{}
+	for _, c := range content {
+		if it, ok := c.(Item); ok {
+			ret = append(ret, it)
+		}
+	}
+	return ret
+}
+
+// textCharacters concatenates the character data of text content,
+// including that of nested elements
+func textCharacters(content []TextContent) string {
+	sb := strings.Builder{}
+	for _, c := range content {
+		switch c := c.(type) {
+		case CharData:
+			sb.WriteString(string(c))
+		case *TSpan:
+			sb.WriteString(textCharacters(c.Content))
+		case *TextPath:
+			sb.WriteString(textCharacters(c.Content))
+		}
+	}
+	return sb.String()
+}
+
+// Text implements SVG <text> element
+type Text struct {
+	Shape
+	TextPositioning
+	Content []TextContent
+}
+
+func (t *Text) read(src sourcer) (err error) {
+	err = t.Shape.read(src)
+	if err != nil {
+		return
+	}
+	err = t.TextPositioning.read(src)
+	if err != nil {
+		return
+	}
+	return readTextContent(src, &t.Content)
+}
+
+func (t *Text) write(tgt targeter) {
+	t.Shape.write(tgt)
+	t.TextPositioning.write(tgt)
+	writeTextContent(tgt, t.Content)
+}
+
+func (t *Text) children() []Item {
+	return textItems(t.Content)
+}
+
+// Characters returns the character data of the text, including that of
+// <tspan> and <textPath> elements
+func (t *Text) Characters() string {
+	return textCharacters(t.Content)
+}
+
+// TSpan implements SVG <tspan> element
+type TSpan struct {
+	item
+	Presentation
+	TextPositioning
+	Content []TextContent
+}
+
+func (t *TSpan) read(src sourcer) (err error) {
+	err = t.item.read(src)
+	if err != nil {
+		return
+	}
+	err = t.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	err = t.TextPositioning.read(src)
+	if err != nil {
+		return
+	}
+	return readTextContent(src, &t.Content)
+}
+
+func (t *TSpan) write(tgt targeter) {
+	t.item.write(tgt)
+	t.Presentation.write(tgt)
+	t.TextPositioning.write(tgt)
+	writeTextContent(tgt, t.Content)
+}
+
+func (t *TSpan) children() []Item {
+	return textItems(t.Content)
+}
+
+// Characters returns the character data of the span, including that of
+// nested elements
+func (t *TSpan) Characters() string {
+	return textCharacters(t.Content)
+}
+
+// TextPath implements SVG <textPath> element
+type TextPath struct {
+	item
+	Presentation
+	Href        string
+	StartOffset Length
+	Content     []TextContent
+}
+
+func (t *TextPath) read(src sourcer) (err error) {
+	err = t.item.read(src)
+	if err != nil {
+		return
+	}
+	err = t.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	t.Href = t.readHref(src)
+	if s, ok := src.Attr("startOffset"); ok {
+		t.StartOffset = Length(s)
+	}
+	return readTextContent(src, &t.Content)
+}
+
+func (t *TextPath) write(tgt targeter) {
+	t.item.write(tgt)
+	t.Presentation.write(tgt)
+	t.writeHref(tgt, t.Href)
+	tgt.Attr("startOffset", string(t.StartOffset))
+	writeTextContent(tgt, t.Content)
+}
+
+func (t *TextPath) children() []Item {
+	return textItems(t.Content)
+}
+
+// Path returns the <path> element referenced by the href attribute, or nil
+// if the reference can not be resolved within doc
+func (t *TextPath) Path(doc *Svg) *Path {
+	p, _ := doc.ByID(hrefID(t.Href)).(*Path)
+	return p
+}
@@ -0,0 +1,99 @@
+package svg
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	data := `<svg viewBox="0 0 40 20">
+		<defs><path id="curve" d="M0,10Q20,0 40,10"/></defs>
+		<g font-family="Verdana, 'DejaVu Sans'" font-size="11">
+			<text id="badge" x="20" y="14" text-anchor="middle" dominant-baseline="central"
+				font-weight="bold" fill="#fff">4<tspan id="sup" dx="1 2" dy="-3" rotate="0 15" font-size="7">2 &amp; more</tspan> </text>
+			<text><textPath id="tp" href="#curve" startOffset="50%">along <tspan>the</tspan> curve</textPath></text>
+		</g>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := doc.Items[1].(*Group)
+	if g.FontFamily != "Verdana, 'DejaVu Sans'" || g.FontSize != "11" {
+		t.Errorf("font-family = %q, font-size = %q", g.FontFamily, g.FontSize)
+	}
+
+	badge := doc.ByID("badge").(*Text)
+	if got := badge.Characters(); got != "42 & more " {
+		t.Errorf("badge characters = %q", got)
+	}
+	if !reflect.DeepEqual(badge.X, LengthList{"20"}) || !reflect.DeepEqual(badge.Y, LengthList{"14"}) {
+		t.Errorf("badge x = %v, y = %v", badge.X, badge.Y)
+	}
+	if badge.TextAnchor == nil || *badge.TextAnchor != TextAnchorMiddle ||
+		badge.DominantBaseline == nil || *badge.DominantBaseline != DominantBaselineCentral ||
+		badge.FontWeight != "bold" {
+		t.Errorf("badge properties = %+v", badge.Presentation)
+	}
+	if len(badge.Content) != 3 || badge.Content[0] != CharData("4") {
+		t.Fatalf("badge content = %#v", badge.Content)
+	}
+
+	sup := doc.ByID("sup").(*TSpan)
+	if !reflect.DeepEqual(sup.Dx, LengthList{"1", "2"}) || !reflect.DeepEqual(sup.Dy, LengthList{"-3"}) ||
+		!reflect.DeepEqual(sup.Rotate, NumberList{0, 15}) || sup.FontSize != "7" {
+		t.Errorf("tspan = %+v", sup)
+	}
+
+	tp := doc.ByID("tp").(*TextPath)
+	if tp.StartOffset != "50%" || tp.Path(doc) != doc.ByID("curve") {
+		t.Errorf("textPath = %+v", tp)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+	for _, want := range []string{
+		`>4<tspan id="sup" font-size="7" dx="1 2" dy="-3" rotate="0 15">2 &amp; more</tspan> </text>`,
+		`<textPath id="tp" href="#curve" startOffset="50%">along <tspan>the</tspan> curve</textPath>`,
+	} {
+		if !bytes.Contains([]byte(out), []byte(want)) {
+			t.Errorf("written document does not contain %s:\n%s", want, out)
+		}
+	}
+
+	for _, v := range []string{"text-before-edge", "text-after-edge", "use-script", "no-change", "reset-size"} {
+		doc, err := Parse(`<svg><text dominant-baseline="` + v + `"/></svg>`)
+		if err != nil {
+			t.Errorf("dominant-baseline %s: %v", v, err)
+			continue
+		}
+		if db := doc.Items[0].(*Text).DominantBaseline; db == nil || db.String() != v {
+			t.Errorf("dominant-baseline %s = %v", v, db)
+		}
+	}
+
+	for _, in := range []string{
+		`<svg><text x="1 a"/></svg>`,
+		`<svg><text rotate="x"/></svg>`,
+		`<svg><text text-anchor="center"/></svg>`,
+		`<svg><text><tspan font-weight="heavy"/></text></svg>`,
+		`<svg><text font-size="tiny"/></svg>`,
+	} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+
+	doc, err = Parse(`<svg><text font-size="small"><tspan style="font-size: larger">a</tspan></text></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txt := doc.Items[0].(*Text)
+	if txt.FontSize != "small" || txt.Content[0].(*TSpan).FontSize != "larger" {
+		t.Errorf("font-size keywords are not kept: %+v", txt)
+	}
+}
@@ -38,6 +38,13 @@ func Scaling(sx, sy float64) *Transform {
 
 func Rotation(a float64) *Transform {
 	s, c := math.Sincos(a)
+	// quarter turns are exact
+	if math.Abs(s) < 1e-15 {
+		s = 0
+	}
+	if math.Abs(c) < 1e-15 {
+		c = 0
+	}
 	return &Transform{
 		A: c, B: s, C: -s, D: c,
 	}
@@ -81,10 +88,36 @@ func (t *Transform) Calc(x, y float64, relative bool) (float64, float64) {
 	return t.A*x + t.C*y + t.E, t.B*x + t.D*y + t.F
 }
 
+// ParseTransform parses the content of the SVG transform attribute and
+// returns the composed transformation matrix
+func ParseTransform(s string) (*Transform, error) {
+	t := UnitTransform()
+	err := t.Unmarshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseTransformAttr parses the value of a transform attribute, an empty
+// value specifies no transform
+func parseTransformAttr(s string) (*Transform, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	return ParseTransform(s)
+}
+
+// Unmarshal parses a transform list and stores the composition of all its
+// transforms in t. Angles are specified in degrees, as in SVG.
 func (t *Transform) Unmarshal(s string) (err error) {
 
+	isWSP := func(c byte) bool {
+		return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f'
+	}
+
 	skipWSP := func() {
-		for len(s) > 0 && (s[0] == ' ' || s[0] == '\t' || s[0] == 'r' || s[0] == '\f') {
+		for len(s) > 0 && isWSP(s[0]) {
 			s = s[1:]
 		}
 	}
@@ -101,6 +134,10 @@ func (t *Transform) Unmarshal(s string) (err error) {
 		return ret
 	}
 
+	deg := func(a float64) float64 {
+		return a * math.Pi / 180
+	}
+
 	tts := []*Transform{}
 
 	skipWSP()
@@ -122,17 +159,9 @@ func (t *Transform) Unmarshal(s string) (err error) {
 		}
 		argstr := s[:cp]
 		s = s[cp+1:]
-		argstr = strings.ReplaceAll(argstr, ",", " ")
-		argstrs := strings.Split(argstr, " ")
-		args := []float64{}
-		for _, arg := range argstrs {
-			if arg != "" {
-				v, err := strconv.ParseFloat(arg, 64)
-				if err != nil {
-					return err
-				}
-				args = append(args, v)
-			}
+		args, err := tokenizePoints(argstr)
+		if err != nil {
+			return err
 		}
 
 		switch cmd {
@@ -155,24 +184,24 @@ func (t *Transform) Unmarshal(s string) (err error) {
 		case "rotate":
 			if len(args) == 1 {
 				tts = append(tts,
-					Rotation(args[0]))
+					Rotation(deg(args[0])))
 			} else if len(args) == 3 {
 				tts = append(tts,
-					Translation(-args[1], -args[2]),
-					Rotation(args[0]),
-					Translation(args[1], args[2]))
+					Translation(args[1], args[2]),
+					Rotation(deg(args[0])),
+					Translation(-args[1], -args[2]))
 			} else {
 				return errors.New("invalid number of arguments in 'rotate' transform")
 			}
 		case "skewX":
 			if len(args) == 1 {
-				tts = append(tts, SkewX(args[0]))
+				tts = append(tts, SkewX(deg(args[0])))
 			} else {
 				return errors.New("invalid number of arguments in 'skewX' transform")
 			}
 		case "skewY":
 			if len(args) == 1 {
-				tts = append(tts, SkewY(args[0]))
+				tts = append(tts, SkewY(deg(args[0])))
 			} else {
 				return errors.New("invalid number of arguments in 'skewY' transform")
 			}
@@ -185,15 +214,61 @@ func (t *Transform) Unmarshal(s string) (err error) {
 		default:
 			return fmt.Errorf("unknown transform '%s'", cmd)
 		}
-	}
 
-	if len(tts) > 0 {
+		// transforms may be separated by a comma
+		skipWSP()
+		if len(s) > 0 && s[0] == ',' {
+			s = s[1:]
+			skipWSP()
+		}
+	}
 
+	ret := UnitTransform()
+	for _, tt := range tts {
+		ret = Concatenate(ret, tt)
 	}
+	*t = *ret
 
 	return nil
 }
 
+// String formats the transform in the SVG transform attribute syntax, using
+// the simplest function that represents the matrix. The values that differ
+// from zero by a rounding error only, such as the cosine of 90 degrees, are
+// written as zeros. The error is relative to the largest coefficient of the
+// matrix, and for the translation, to the largest translation as well.
+func (t *Transform) String() string {
+	f := formatNumber
+	c := *t
+	scale := math.Max(math.Max(math.Abs(c.A), math.Abs(c.B)), math.Max(math.Abs(c.C), math.Abs(c.D)))
+	offset := math.Max(scale, math.Max(math.Abs(c.E), math.Abs(c.F)))
+	for _, v := range []*float64{&c.A, &c.B, &c.C, &c.D} {
+		if math.Abs(*v) < scale*1e-12 {
+			*v = 0
+		}
+	}
+	for _, v := range []*float64{&c.E, &c.F} {
+		if math.Abs(*v) < offset*1e-12 {
+			*v = 0
+		}
+	}
+	t = &c
+	if t.A == 1 && t.B == 0 && t.C == 0 && t.D == 1 {
+		if t.F == 0 {
+			return "translate(" + f(t.E) + ")"
+		}
+		return "translate(" + f(t.E) + "," + f(t.F) + ")"
+	}
+	if t.B == 0 && t.C == 0 && t.E == 0 && t.F == 0 {
+		if t.A == t.D {
+			return "scale(" + f(t.A) + ")"
+		}
+		return "scale(" + f(t.A) + "," + f(t.D) + ")"
+	}
+	return "matrix(" + f(t.A) + "," + f(t.B) + "," + f(t.C) + "," +
+		f(t.D) + "," + f(t.E) + "," + f(t.F) + ")"
+}
+
 type ViewBox string
 
 type ViewBoxValue struct {
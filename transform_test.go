@@ -0,0 +1,250 @@
+package svg
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sameTransform(a, b *Transform) bool {
+	const eps = 1e-9
+	return math.Abs(a.A-b.A) < eps && math.Abs(a.B-b.B) < eps &&
+		math.Abs(a.C-b.C) < eps && math.Abs(a.D-b.D) < eps &&
+		math.Abs(a.E-b.E) < eps && math.Abs(a.F-b.F) < eps
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *Transform
+	}{
+		{"", UnitTransform()},
+		{"translate(10)", Translation(10, 0)},
+		{"translate(10,-5)", Translation(10, -5)},
+		{"scale(2)", Scaling(2, 2)},
+		{"rotate(90)", &Transform{A: 0, B: 1, C: -1, D: 0}},
+		{"rotate(90 10 10)", &Transform{A: 0, B: 1, C: -1, D: 0, E: 20, F: 0}},
+		{"translate(10 20), scale(2)", &Transform{A: 2, D: 2, E: 10, F: 20}},
+		{"\n\tscale(2)\n\ttranslate(10 20)", &Transform{A: 2, D: 2, E: 20, F: 40}},
+		{"matrix(1 2 3 4 5 6)", &Transform{1, 2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTransform(tt.in)
+		if err != nil {
+			t.Errorf("ParseTransform(%q): %s", tt.in, err)
+			continue
+		}
+		if !sameTransform(got, tt.want) {
+			t.Errorf("ParseTransform(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"rotate(1 2)", "translate(1", "foo(1)", "scale()"} {
+		if _, err := ParseTransform(in); err == nil {
+			t.Errorf("ParseTransform(%q): expected an error", in)
+		}
+	}
+}
+
+func TestTransformString(t *testing.T) {
+	for _, in := range []string{
+		"translate(10)", "translate(10,-5)", "scale(2)", "scale(2,3)",
+		"matrix(1,2,3,4,5,6)",
+	} {
+		tr, err := ParseTransform(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := tr.String(); s != in {
+			t.Errorf("String() = %q, want %q", s, in)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"rotate(90)": "matrix(0,1,-1,0,0,0)",
+	} {
+		tr, err := ParseTransform(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := tr.String(); s != want {
+			t.Errorf("%s: String() = %q, want %q", in, s, want)
+		}
+	}
+	tr := &Transform{A: 2, B: 6.123233995736766e-17, C: -1e-16, D: 2, E: 5, F: 1e-13}
+	if s := tr.String(); s != "matrix(2,0,0,2,5,0)" {
+		t.Errorf("String() = %q, want rounding noise written as zeros", s)
+	}
+	tr = &Transform{A: 1e-13, D: 1e-13, E: 1e-28}
+	if s := tr.String(); s != "scale(1e-13)" {
+		t.Errorf("String() = %q, want small scale kept", s)
+	}
+
+	doc, doc2, out := roundTrip(t, `<svg><g transform=" "/><rect transform="translate(0)"/></svg>`)
+	if doc.Items[0].(*Group).Transform != nil || !reflect.DeepEqual(doc, doc2) ||
+		!strings.Contains(out, "<g />") || !strings.Contains(out, `<rect transform="translate(0)" />`) {
+		t.Errorf("empty transform is written:\n%s", out)
+	}
+}
+
+func TestTransformRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/transform.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, ok := doc.Items[0].(*Group)
+	if !ok || g.Transform == nil {
+		t.Fatal("missing group transform")
+	}
+	want, _ := ParseTransform("rotate(-10 50 100) translate(-36 45.5) skewX(40) scale(1 0.5)")
+	if !sameTransform(g.Transform, want) {
+		t.Errorf("group transform = %v, want %v", g.Transform, want)
+	}
+
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	if !strings.Contains(buf.String(), `transform="matrix(`) {
+		t.Errorf("transform is not written: %s", buf.String())
+	}
+	doc2, err := Parse(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g2 := doc2.Items[0].(*Group); g2.Transform == nil || !sameTransform(g2.Transform, want) {
+		t.Errorf("transform does not survive a round trip")
+	}
+}
+
+func TestViewportTransform(t *testing.T) {
+	vb := &ViewBoxValue{MinX: 0, MinY: 0, Width: 10, Height: 20}
+	tests := []struct {
+		par  string
+		want *Transform
+	}{
+		{"", &Transform{A: 2, D: 2, E: 0, F: 20}}, // xMidYMid meet
+		{"xMidYMid", &Transform{A: 2, D: 2, E: 0, F: 20}},
+		{"xMinYMin meet", &Transform{A: 2, D: 2}},
+		{"xMaxYMax", &Transform{A: 2, D: 2, E: 0, F: 40}},
+		{"xMidYMid slice", &Transform{A: 4, D: 4, E: -10, F: 0}},
+		{"xMinYMax slice", &Transform{A: 4, D: 4, E: 0, F: 0}},
+		{"xMaxYMin slice", &Transform{A: 4, D: 4, E: -20, F: 0}},
+		{"none", &Transform{A: 2, D: 4}},
+	}
+	for _, tt := range tests {
+		var par *PreserveAspectRatio
+		if tt.par != "" {
+			var err error
+			par, err = ParsePreserveAspectRatio(tt.par)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		// viewport: 20 x 80 at the origin, shifted by (5, 5)
+		got := ViewportTransform(vb, par, 0, 0, 20, 80)
+		if !sameTransform(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.par, got, tt.want)
+		}
+		got = ViewportTransform(vb, par, 5, 5, 20, 80)
+		if want := Concatenate(Translation(5, 5), tt.want); !sameTransform(got, want) {
+			t.Errorf("%q: got %v, want %v", tt.par, got, want)
+		}
+	}
+
+	// viewBox origin is mapped to the viewport origin
+	got := ViewportTransform(&ViewBoxValue{MinX: -40, MinY: 10, Width: 150, Height: 100}, nil, 0, 0, 150, 100)
+	if want := Translation(40, -10); !sameTransform(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := ViewportTransform(nil, nil, 1, 2, 3, 4); !sameTransform(got, Translation(1, 2)) {
+		t.Errorf("got %v without viewBox", got)
+	}
+
+	for _, in := range []string{"", "xMidYMid foo", "xmidymid", "none meet slice"} {
+		if _, err := ParsePreserveAspectRatio(in); err == nil {
+			t.Errorf("ParsePreserveAspectRatio(%q): expected an error", in)
+		}
+	}
+	for _, in := range []string{"xMinYMax", "none", "xMidYMid slice"} {
+		par, err := ParsePreserveAspectRatio(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if par.String() != in {
+			t.Errorf("String() = %q, want %q", par.String(), in)
+		}
+	}
+}
+
+func TestNestedViewport(t *testing.T) {
+	data := `<svg viewBox="0 0 200 100" width="200" height="100">
+		<svg id="left" width="50%" viewBox="0 0 10 10">
+			<circle id="c" cx="5" cy="5" r="5"/>
+		</svg>
+		<svg id="right" x="100" y="10%" width="100" height="50" viewBox="0 0 10 10"
+			preserveAspectRatio="xMinYMid slice" overflow="visible">
+			<svg id="inner" width="5" height="5"><rect width="1" height="1"/></svg>
+		</svg>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left := doc.ByID("left").(*Svg)
+	if x, y, w, h := left.Viewport(200, 100); x != 0 || y != 0 || w != 100 || h != 100 {
+		t.Errorf("left viewport = %v %v %v %v", x, y, w, h)
+	}
+	if got := left.NestedViewportTransform(200, 100); !sameTransform(got, Scaling(10, 10)) {
+		t.Errorf("left transform = %v", got)
+	}
+	if !left.ClipsOverflow() || doc.ByID("c") == nil {
+		t.Errorf("left = %+v", left)
+	}
+
+	right := doc.ByID("right").(*Svg)
+	if got, want := right.NestedViewportTransform(200, 100), (&Transform{A: 10, D: 10, E: 100, F: -15}); !sameTransform(got, want) {
+		t.Errorf("right transform = %v, want %v", got, want)
+	}
+	if right.ClipsOverflow() {
+		t.Errorf("overflow = %v", right.Overflow)
+	}
+	inner := right.Items[0].(*Svg)
+	if got := inner.NestedViewportTransform(10, 10); !sameTransform(got, UnitTransform()) {
+		t.Errorf("inner transform = %v", got)
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+	for _, tt := range []struct {
+		overflow string
+		clips    bool
+	}{
+		{"hidden", true},
+		{"scroll", true},
+		{"clip", true},
+		{"auto", false},
+		{"visible", false},
+	} {
+		doc, err := Parse(`<svg><svg overflow="` + tt.overflow + `"/></svg>`)
+		if err != nil {
+			t.Errorf("overflow %s: %v", tt.overflow, err)
+			continue
+		}
+		nested := doc.Items[0].(*Svg)
+		if nested.ClipsOverflow() != tt.clips || nested.Overflow.String() != tt.overflow {
+			t.Errorf("overflow %s: clips = %v", tt.overflow, nested.ClipsOverflow())
+		}
+	}
+	if _, err := Parse(`<svg><svg overflow="sideways"/></svg>`); err == nil {
+		t.Errorf("expected an error for invalid overflow")
+	}
+}
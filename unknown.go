@@ -0,0 +1,87 @@
+package svg
+
+import "strings"
+
+// Unknown holds an element that is not supported, so that it can be written
+// back as is. Its attributes are kept in ExtraAttrs, and its content is kept
+// verbatim as XML markup.
+type Unknown struct {
+	item
+	Tag     string
+	Content string
+}
+
+func (u *Unknown) read(src sourcer) (err error) {
+	err = u.item.read(src)
+	if err != nil {
+		return
+	}
+	u.Content, err = src.RawContent()
+	return
+}
+
+func (u *Unknown) write(tgt targeter) {
+	u.item.write(tgt)
+	tgt.Raw(u.Content)
+}
+
+// Comment holds an XML comment, so that it can be written back. A double
+// dash, which is not allowed within comments, is written as a single one.
+type Comment struct {
+	Text string
+}
+
+func (c *Comment) ID() string {
+	return ""
+}
+
+func (c *Comment) write(tgt targeter) {
+	tgt.Raw("<!--" + strings.ReplaceAll(c.Text, "--", "-") + "-->")
+}
+
+// extraChildren holds the children of an element that does not model them,
+// such as <animate> or a comment within a gradient, so that these can be
+// written back at their positions among the modeled children
+type extraChildren struct {
+	// Items holds the children other than the modeled ones, the items that
+	// are appended are written after the modeled children
+	Items []Item
+
+	at []int // number of modeled children that precede each of Items
+}
+
+// readExtra reads a child element that is not modeled, n is the number of
+// modeled children read so far
+func (e *extraChildren) readExtra(tag string, cs sourcer, n int) error {
+	it := newItem(tag)
+	if err := readItem(it, cs); err != nil {
+		return err
+	}
+	e.Items = append(e.Items, it)
+	e.at = append(e.at, n)
+	return nil
+}
+
+// readComment keeps a comment, n is the number of modeled children read so
+// far
+func (e *extraChildren) readComment(text string, n int) error {
+	e.Items = append(e.Items, &Comment{Text: text})
+	e.at = append(e.at, n)
+	return nil
+}
+
+// writeAround writes n modeled children with writeChild, and the extra items
+// in between
+func (e *extraChildren) writeAround(tgt targeter, n int, writeChild func(i int)) {
+	j := 0
+	for i := 0; ; i++ {
+		for j < len(e.Items) && (i == n || j < len(e.at) && e.at[j] <= i) {
+			writeChildren(tgt, e.Items[j:j+1])
+			j++
+		}
+		if i == n {
+			return
+		}
+		writeChild(i)
+	}
+}
@@ -0,0 +1,141 @@
+package svg
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLosslessRoundTrip(t *testing.T) {
+	data := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"
+		xmlns:sodipodi="http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd"
+		version="1.1" viewBox="0 0 24 24" xml:space="preserve" aria-label="Badge" role="img">
+		<sodipodi:namedview id="base" pagecolor="#ffffff" inkscape:zoom="8"/>
+		<g inkscape:groupmode="layer" inkscape:label="Layer 1" data-state="">
+			<rect width="10" height="10" style="fill:#f00;font-variant:normal;-inkscape-stroke:none"
+				data-x="1 &amp; 2"><title>Box</title><animate attributeName="x" to="5"/></rect>
+			<foreignObject width="10" height="10"><div xmlns="http://www.w3.org/1999/xhtml">a <b>b</b></div></foreignObject>
+			<text>1<tspan sodipodi:role="line">2</tspan><a href="#x">3</a></text>
+		</g>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := doc.ExtraAttr("xml:space"); !ok || v != "preserve" {
+		t.Errorf("xml:space = %q, %v", v, ok)
+	}
+	if got := doc.AccessibleName(); got != "Badge" {
+		t.Errorf("accessible name = %q", got)
+	}
+	nv := doc.Items[0].(*Unknown)
+	if nv.Tag != "sodipodi:namedview" || nv.ID() != "base" || len(nv.ExtraAttrs) != 2 {
+		t.Errorf("unknown = %+v", nv)
+	}
+	g := doc.Items[1].(*Group)
+	if v, ok := g.ExtraAttr("data-state"); !ok || v != "" {
+		t.Errorf("data-state = %q, %v", v, ok)
+	}
+	r := g.Items[0].(*Rect)
+	if r.Fill == nil || len(r.ExtraAttrs) != 1 || r.ExtraAttrs[0].Value != "1 & 2" || len(r.Items) != 2 {
+		t.Errorf("rect = %+v", r)
+	}
+	fo := g.Items[1].(*Unknown)
+	if fo.Content != `<div xmlns="http://www.w3.org/1999/xhtml">a <b>b</b></div>` {
+		t.Errorf("foreignObject content = %q", fo.Content)
+	}
+
+	for _, opts := range [][]WriteOption{nil, {WithPropertyFormat(PropertiesAsStyle)}} {
+		doc, doc2, out := roundTrip(t, data, opts...)
+		if !reflect.DeepEqual(doc, doc2) {
+			t.Errorf("round trip mismatch, written document:\n%s", out)
+		}
+		for _, want := range []string{
+			`inkscape:groupmode="layer" inkscape:label="Layer 1" data-state=""`,
+			`font-variant:normal;-inkscape-stroke:none`,
+			`<rect data-x="1 &amp; 2"`,
+			`<title>Box</title><animate attributeName="x" to="5" /></rect>`,
+			`<tspan sodipodi:role="line">2</tspan><a href="#x">3</a>`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("written document does not contain %s:\n%s", want, out)
+			}
+		}
+	}
+
+	r.SetExtraAttr("data-x", "3")
+	r.SetExtraAttr("aria-hidden", "true")
+	r.DeleteExtraAttr("nothing")
+	g.DeleteExtraAttr("data-state")
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	if out := buf.String(); !strings.Contains(out, `data-x="3" aria-hidden="true"`) || strings.Contains(out, "data-state") {
+		t.Errorf("edited attributes are not written:\n%s", out)
+	}
+}
+
+func TestKeepUnmodeledContent(t *testing.T) {
+	data := `<svg>
+		<linearGradient id="lg"><title>Fade</title><stop offset="0"/><!-- mid --><animate attributeName="x1" to="1"/><stop offset="1"/><set to="2"/></linearGradient>
+		<filter id="f"><feMerge><feMergeNode in="a"/><animate attributeName="in"/><feMergeNode in="b"/></feMerge><!-- blur --></filter>
+		<g><!-- group --><rect/></g>
+		<text>a<!-- text -->b</text>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg := doc.ByID("lg").(*LinearGradient)
+	if len(lg.Stops) != 2 || len(lg.Items) != 4 {
+		t.Fatalf("gradient = %+v", lg)
+	}
+	if c, ok := lg.Items[1].(*Comment); !ok || c.Text != " mid " {
+		t.Errorf("gradient comment = %+v", lg.Items[1])
+	}
+	if u, ok := lg.Items[2].(*Unknown); !ok || u.Tag != "animate" {
+		t.Errorf("gradient animation = %+v", lg.Items[2])
+	}
+	if len(doc.Items[2].(*Group).Items) != 2 {
+		t.Errorf("group items = %+v", doc.Items[2].(*Group).Items)
+	}
+
+	for _, opts := range [][]WriteOption{nil, {WithPropertyFormat(PropertiesAsStyle)}} {
+		doc, doc2, out := roundTrip(t, data, opts...)
+		if !reflect.DeepEqual(doc, doc2) {
+			t.Errorf("round trip mismatch, written document:\n%s", out)
+		}
+		for _, want := range []string{
+			`<title>Fade</title><stop offset="0" /><!-- mid --><animate attributeName="x1" to="1" /><stop offset="1" /><set to="2" /></linearGradient>`,
+			`<feMergeNode in="a" /><animate attributeName="in" /><feMergeNode in="b" /></feMerge><!-- blur --></filter>`,
+			`<g><!-- group --><rect /></g>`,
+			`<text>a<!-- text -->b</text>`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("written document does not contain %s:\n%s", want, out)
+			}
+		}
+	}
+}
+
+func TestKeepProlog(t *testing.T) {
+	doc, err := ParseFile("testdata/close_cross.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<!-- Generator: Adobe Illustrator 20.1.0, SVG Export Plug-In . SVG Version: 6.00 Build 0)  -->
+<svg `
+	if out := buf.String(); !strings.HasPrefix(out, want) {
+		t.Errorf("written document does not start with the prolog:\n%s", out)
+	}
+
+	doc, doc2, out := roundTrip(t, "<svg/>\n<!-- end -->\n")
+	if !reflect.DeepEqual(doc, doc2) || out != "<svg />\n<!-- end -->" {
+		t.Errorf("written document does not end with the comment:\n%s", out)
+	}
+}
@@ -0,0 +1,200 @@
+package svg
+
+import (
+	"fmt"
+)
+
+// Use implements SVG <use> element
+type Use struct {
+	shapeNode
+	Href   string
+	X      Coordinate
+	Y      Coordinate
+	Width  Length
+	Height Length
+}
+
+func (u *Use) read(src sourcer) (err error) {
+	err = u.Shape.read(src)
+	if err != nil {
+		return
+	}
+	u.Href = u.readHref(src)
+	if s, ok := src.Attr("x"); ok {
+		u.X = Coordinate(s)
+	}
+	if s, ok := src.Attr("y"); ok {
+		u.Y = Coordinate(s)
+	}
+	if s, ok := src.Attr("width"); ok {
+		u.Width = Length(s)
+	}
+	if s, ok := src.Attr("height"); ok {
+		u.Height = Length(s)
+	}
+	return readChildren(src, &u.Items)
+}
+
+func (u *Use) write(tgt targeter) {
+	u.Shape.write(tgt)
+	u.writeHref(tgt, u.Href)
+	tgt.Attr("x", string(u.X))
+	tgt.Attr("y", string(u.Y))
+	tgt.Attr("width", string(u.Width))
+	tgt.Attr("height", string(u.Height))
+	writeChildren(tgt, u.Items)
+}
+
+// Expand creates an instance of the element referenced by u. The instance
+// is a deep copy of the referenced element, wrapped into a group that carries
+// the presentation properties and the transform of u, with the additional
+// translation by x and y. The copy inherits properties from the group, as it
+// would inherit them from the <use> element. Percentages in x and y refer to
+// the size of the viewport that u is placed into, font-relative units are
+// not supported. Nested <use> elements are expanded as well. The copies do
+// not keep the ids of the originals, so that the ids stay unique within the
+// document.
+//
+// A referenced <symbol> is instantiated as a group that maps the symbol
+// content into the viewport established by the width and height of u. These
+// default to the width and height of the symbol, and then to 100%.
+func (u *Use) Expand(doc *Svg) (*Group, error) {
+	w, h := doc.viewportSize(u)
+	return u.expand(doc, nil, w, h)
+}
+
+// expand instantiates the referenced element, vw and vh hold the size of the
+// viewport that u is placed into
+func (u *Use) expand(doc *Svg, stack []Item, vw, vh float64) (*Group, error) {
+	id := hrefID(u.Href)
+	target := doc.ByID(id)
+	if target == nil {
+		return nil, fmt.Errorf("<use> references missing element '%s'", u.Href)
+	}
+	for _, it := range stack {
+		if it == target {
+			return nil, fmt.Errorf("<use> reference cycle at '%s'", u.Href)
+		}
+	}
+	stack = append(stack, target)
+
+	g := &Group{}
+	g.Presentation = deepClone(u.Presentation)
+	if u.Transform != nil {
+		t := *u.Transform
+		g.Transform = &t
+	}
+	x, err := resolveUseLength(u.X, vw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x of <use>: %w", err)
+	}
+	y, err := resolveUseLength(u.Y, vh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y of <use>: %w", err)
+	}
+	if x != 0 || y != 0 {
+		if g.Transform == nil {
+			g.Transform = Translation(x, y)
+		} else {
+			g.Transform = Concatenate(g.Transform, Translation(x, y))
+		}
+	}
+
+	instance := cloneItem(target)
+	Walk(instance, func(it Item) bool {
+		// the copies would duplicate the ids of the originals
+		if b, ok := it.(interface{ base() *item }); ok {
+			b.base().id = ""
+		}
+		return true
+	})
+	switch inst := instance.(type) {
+	case *Use:
+		ng, err := inst.expand(doc, stack, vw, vh)
+		if err != nil {
+			return nil, err
+		}
+		instance = ng
+	case *Symbol:
+		instance, vw, vh = u.instantiateSymbol(inst, vw, vh)
+	}
+	if err := expandNested(doc, instance, stack, vw, vh); err != nil {
+		return nil, err
+	}
+	g.Items = []Item{instance}
+	return g, nil
+}
+
+// instantiateSymbol converts a copy of the referenced symbol into a group
+// that maps the symbol content into the viewport established by u, vw and vh
+// hold the size of the viewport that u is placed into. The size of the
+// viewport that the content is placed into is returned as well.
+func (u *Use) instantiateSymbol(s *Symbol, vw, vh float64) (g *Group, cw, ch float64) {
+	w := resolveLength(u.Width, vw, resolveLength(s.Width, vw, vw))
+	h := resolveLength(u.Height, vh, resolveLength(s.Height, vh, vh))
+	x := resolveLength(s.X, vw, 0)
+	y := resolveLength(s.Y, vh, 0)
+	g = &Group{
+		Node:         s.Node,
+		Presentation: s.Presentation,
+		Transform:    s.ViewportTransform(x, y, w, h),
+	}
+	cw, ch = w, h
+	if s.ViewBox != nil {
+		cw, ch = s.ViewBox.Width, s.ViewBox.Height
+	}
+	return
+}
+
+// userSize returns the first length that is specified in absolute units, or
+// the fallback value. Relative lengths cannot be resolved without the
+// context of the referencing element.
+func userSize(fallback float64, ll ...Length) float64 {
+	for _, l := range ll {
+		v, u, err := l.AsNumeric()
+		if err == nil && u != UnitPercent && u != UnitEM && u != UnitEX {
+			return v
+		}
+	}
+	return fallback
+}
+
+// resolveUseLength converts x or y of <use> into user units, percentages are
+// resolved against the size of the viewport
+func resolveUseLength(l Length, ref float64) (float64, error) {
+	if l == "" {
+		return 0, nil
+	}
+	_, u, err := l.AsNumeric()
+	if err != nil {
+		return 0, err
+	}
+	if u == UnitEM || u == UnitEX {
+		return 0, fmt.Errorf("unsupported units in '%s'", l)
+	}
+	return resolveLength(l, ref, 0), nil
+}
+
+// expandNested replaces <use> elements within the tree rooted at it with
+// their instances
+func expandNested(doc *Svg, it Item, stack []Item, vw, vh float64) (err error) {
+	Walk(it, func(it Item) bool {
+		n, ok := it.(interface{ node() *Node })
+		if !ok || err != nil {
+			return err == nil
+		}
+		items := n.node().Items
+		for i, ch := range items {
+			if u, ok := ch.(*Use); ok {
+				var g *Group
+				g, err = u.expand(doc, stack, vw, vh)
+				if err != nil {
+					return false
+				}
+				items[i] = g
+			}
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,172 @@
+package svg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUseExpand(t *testing.T) {
+	data := `<svg xmlns:xlink="http://www.w3.org/1999/xlink">
+		<defs>
+			<linearGradient id="grad"><stop offset="0" stop-color="red"/></linearGradient>
+			<g id="icon" stroke="blue">
+				<rect id="box" data-k="1" width="10" height="10" style="fill:url(#grad);paint-order:stroke"/>
+			</g>
+			<g id="pair">
+				<use xlink:href="#icon"/>
+				<use href="#icon" x="20"/>
+			</g>
+			<g id="loop"><use href="#loop"/></g>
+		</defs>
+		<use id="u1" href="#icon" x="5" y="6" fill="green" transform="scale(2)"/>
+		<use id="u2" href="#pair"/>
+		<use id="u3" href="#loop"/>
+		<use id="u4" href="#missing"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u1 := doc.ByID("u1").(*Use)
+	if u1.Href != "#icon" || u1.X != "5" || u1.Y != "6" || u1.Fill == nil {
+		t.Errorf("use = %+v", u1)
+	}
+
+	g, err := u1.Expand(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Fill == nil || g.Fill.Color != (RGB{0, 0x80, 0}) {
+		t.Errorf("instance does not inherit use properties: %v", g.Fill)
+	}
+	if want := (&Transform{A: 2, D: 2, E: 10, F: 12}); g.Transform == nil || *g.Transform != *want {
+		t.Errorf("instance transform = %v, want %v", g.Transform, want)
+	}
+	icon := doc.ByID("icon").(*Group)
+	inst, ok := g.Items[0].(*Group)
+	if !ok || inst == icon || inst.Stroke == nil || len(inst.Items) != 1 {
+		t.Fatalf("instance is not a copy of the target")
+	}
+	Walk(g, func(it Item) bool {
+		if it.ID() != "" {
+			t.Errorf("instance keeps the id %q", it.ID())
+		}
+		return true
+	})
+	box := inst.Items[0].(*Rect)
+	if box == icon.Items[0] || box.Fill == icon.Items[0].(*Rect).Fill {
+		t.Errorf("instance is not a deep copy")
+	}
+	if box.Fill.Server != doc.ByID("grad") {
+		t.Errorf("paint server binding should be kept")
+	}
+	box.Width = "20"
+	box.ExtraAttrs[0].Value = "2"
+	box.extraStyle[0].Value = "fill"
+	box.sources["fill"] = propertySource{}
+	if orig := icon.Items[0].(*Rect); orig.Width != "10" || orig.ExtraAttrs[0].Value != "1" || orig.extraStyle[0].Value != "stroke" ||
+		orig.sources["fill"].value == "" {
+		t.Errorf("modifying the instance changes the original")
+	}
+
+	g, err = doc.ByID("u2").(*Use).Expand(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair := g.Items[0].(*Group)
+	if len(pair.Items) != 2 {
+		t.Fatalf("pair = %+v", pair)
+	}
+	for i, it := range pair.Items {
+		ng, ok := it.(*Group)
+		if !ok || len(ng.Items) != 1 || ng.Items[0].(*Group).Stroke == nil {
+			t.Errorf("nested use %d is not expanded: %+v", i, it)
+		}
+	}
+	if tr := pair.Items[1].(*Group).Transform; tr == nil || tr.E != 20 {
+		t.Errorf("nested use transform = %v", tr)
+	}
+	if _, ok := doc.ByID("pair").(*Group).Items[0].(*Use); !ok {
+		t.Errorf("expansion modifies the original")
+	}
+
+	if _, err = doc.ByID("u3").(*Use).Expand(doc); err == nil {
+		t.Errorf("expected a cycle error")
+	}
+	if _, err = doc.ByID("u4").(*Use).Expand(doc); err == nil {
+		t.Errorf("expected a missing reference error")
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+}
+
+func TestUseSymbol(t *testing.T) {
+	data := `<svg viewBox="0 0 48 48" preserveAspectRatio="xMinYMin slice">
+		<symbol id="icon" viewBox="0 0 24 24" fill="red">
+			<path d="M0,0L24,24"/>
+		</symbol>
+		<use id="big" href="#icon" x="8" width="48" height="48"/>
+		<use id="natural" href="#icon"/>
+		<use id="pct" href="#icon" x="50%" y="10" width="24" height="24"/>
+		<use id="em" href="#icon" y="2em"/>
+	</svg>`
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ViewBox == nil || doc.ViewBox.Width != 48 || doc.PreserveAspectRatio == nil ||
+		!doc.PreserveAspectRatio.Slice {
+		t.Errorf("viewBox = %v, preserveAspectRatio = %v", doc.ViewBox, doc.PreserveAspectRatio)
+	}
+	if got := doc.ViewportTransform(96, 192); !sameTransform(got, Scaling(4, 4)) {
+		t.Errorf("root viewport transform = %v", got)
+	}
+
+	sym := doc.ByID("icon").(*Symbol)
+	if sym.ViewBox == nil || sym.ViewBox.Width != 24 || len(sym.Items) != 1 || sym.Fill == nil {
+		t.Fatalf("symbol = %+v", sym)
+	}
+
+	g, err := doc.ByID("big").(*Use).Expand(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Translation(8, 0); !sameTransform(g.Transform, want) {
+		t.Errorf("use transform = %v, want %v", g.Transform, want)
+	}
+	inst := g.Items[0].(*Group)
+	if !sameTransform(inst.Transform, Scaling(2, 2)) || inst.Fill == nil || len(inst.Items) != 1 {
+		t.Errorf("symbol instance = %+v", inst)
+	}
+
+	g, err = doc.ByID("natural").(*Use).Expand(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the size defaults to 100% of the viewport, rather than to the viewBox
+	// of the symbol
+	if inst := g.Items[0].(*Group); !sameTransform(inst.Transform, Scaling(2, 2)) {
+		t.Errorf("symbol instance transform = %v", inst.Transform)
+	}
+
+	g, err = doc.ByID("pct").(*Use).Expand(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Translation(24, 10); !sameTransform(g.Transform, want) {
+		t.Errorf("use transform = %v, want %v", g.Transform, want)
+	}
+	if _, err = doc.ByID("em").(*Use).Expand(doc); err == nil {
+		t.Errorf("expected an error for font-relative units")
+	}
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+}
@@ -0,0 +1,376 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ParseViewBox parses the content of the viewBox attribute
+func ParseViewBox(s string) (*ViewBoxValue, error) {
+	vb, err := ViewBox(s).Parse()
+	if err != nil {
+		return nil, err
+	}
+	if vb.Width < 0 || vb.Height < 0 {
+		return nil, errors.New("negative viewBox size")
+	}
+	return vb, nil
+}
+
+func (vb *ViewBoxValue) String() string {
+	return formatNumber(vb.MinX) + " " + formatNumber(vb.MinY) + " " +
+		formatNumber(vb.Width) + " " + formatNumber(vb.Height)
+}
+
+// Align implements the alignment part of SVG preserveAspectRatio attribute
+type Align int
+
+const (
+	AlignXMidYMid = Align(iota) // default
+	AlignNone
+	AlignXMinYMin
+	AlignXMidYMin
+	AlignXMaxYMin
+	AlignXMinYMid
+	AlignXMaxYMid
+	AlignXMinYMax
+	AlignXMidYMax
+	AlignXMaxYMax
+)
+
+var alignNames = map[Align]string{
+	AlignXMidYMid: "xMidYMid",
+	AlignNone:     "none",
+	AlignXMinYMin: "xMinYMin",
+	AlignXMidYMin: "xMidYMin",
+	AlignXMaxYMin: "xMaxYMin",
+	AlignXMinYMid: "xMinYMid",
+	AlignXMaxYMid: "xMaxYMid",
+	AlignXMinYMax: "xMinYMax",
+	AlignXMidYMax: "xMidYMax",
+	AlignXMaxYMax: "xMaxYMax",
+}
+
+func (a Align) String() string {
+	return alignNames[a]
+}
+
+// factors returns alignment of the viewBox within the viewport along x and y
+// axes, 0 for min, 0.5 for mid, and 1 for max
+func (a Align) factors() (fx, fy float64) {
+	switch a {
+	case AlignXMinYMin, AlignXMinYMid, AlignXMinYMax:
+		fx = 0
+	case AlignXMaxYMin, AlignXMaxYMid, AlignXMaxYMax:
+		fx = 1
+	default:
+		fx = 0.5
+	}
+	switch a {
+	case AlignXMinYMin, AlignXMidYMin, AlignXMaxYMin:
+		fy = 0
+	case AlignXMinYMax, AlignXMidYMax, AlignXMaxYMax:
+		fy = 1
+	default:
+		fy = 0.5
+	}
+	return
+}
+
+// PreserveAspectRatio implements SVG preserveAspectRatio attribute, the zero
+// value corresponds to the default 'xMidYMid meet'
+type PreserveAspectRatio struct {
+	Align Align
+	Slice bool
+}
+
+// ParsePreserveAspectRatio parses the content of preserveAspectRatio
+// attribute
+func ParsePreserveAspectRatio(s string) (*PreserveAspectRatio, error) {
+	ff := strings.Fields(s)
+	if len(ff) > 0 && ff[0] == "defer" {
+		// only applies to <image> referencing another svg, ignored
+		ff = ff[1:]
+	}
+	if len(ff) < 1 || len(ff) > 2 {
+		return nil, errors.New("invalid number of values")
+	}
+	par := &PreserveAspectRatio{Align: -1}
+	for a, n := range alignNames {
+		if n == ff[0] {
+			par.Align = a
+		}
+	}
+	if par.Align < 0 {
+		return nil, errors.New("invalid alignment value")
+	}
+	if len(ff) == 2 {
+		switch ff[1] {
+		case "meet":
+		case "slice":
+			par.Slice = true
+		default:
+			return nil, errors.New("invalid meetOrSlice value")
+		}
+	}
+	return par, nil
+}
+
+func (par *PreserveAspectRatio) String() string {
+	if par.Slice {
+		return par.Align.String() + " slice"
+	}
+	return par.Align.String()
+}
+
+// ViewportTransform computes the transform that maps the viewBox onto the
+// viewport at (x, y) with the size of width by height, as specified by
+// preserveAspectRatio. The viewBox and preserveAspectRatio are optional: if
+// the viewBox is nil, the content is only translated to the viewport origin.
+func ViewportTransform(vb *ViewBoxValue, par *PreserveAspectRatio, x, y, width, height float64) *Transform {
+	if vb == nil || vb.Width <= 0 || vb.Height <= 0 {
+		return Translation(x, y)
+	}
+	if par == nil {
+		par = &PreserveAspectRatio{}
+	}
+
+	sx := width / vb.Width
+	sy := height / vb.Height
+	if par.Align != AlignNone {
+		if par.Slice {
+			sx = math.Max(sx, sy)
+		} else {
+			sx = math.Min(sx, sy)
+		}
+		sy = sx
+	}
+
+	tx := x - vb.MinX*sx
+	ty := y - vb.MinY*sy
+	if par.Align != AlignNone {
+		fx, fy := par.Align.factors()
+		tx += (width - vb.Width*sx) * fx
+		ty += (height - vb.Height*sy) * fy
+	}
+	return &Transform{A: sx, D: sy, E: tx, F: ty}
+}
+
+// Overflow implements SVG overflow property value
+type Overflow int
+
+const (
+	OverflowVisible = Overflow(iota)
+	OverflowHidden
+	OverflowScroll
+	OverflowAuto
+	OverflowClip
+)
+
+func (o Overflow) String() string {
+	switch o {
+	case OverflowVisible:
+		return "visible"
+	case OverflowHidden:
+		return "hidden"
+	case OverflowScroll:
+		return "scroll"
+	case OverflowAuto:
+		return "auto"
+	case OverflowClip:
+		return "clip"
+	default:
+		return ""
+	}
+}
+
+func (o *Overflow) UnmarshalText(text []byte) error {
+	s := string(text)
+	switch s {
+	case "visible":
+		*o = OverflowVisible
+	case "hidden":
+		*o = OverflowHidden
+	case "scroll":
+		*o = OverflowScroll
+	case "auto":
+		*o = OverflowAuto
+	case "clip":
+		*o = OverflowClip
+	default:
+		return errors.New("invalid overflow value")
+	}
+	return nil
+}
+
+// resolveLength converts a length into user units, percentages are
+// resolved against ref. The fallback value is returned for lengths that are
+// not specified or can not be resolved.
+func resolveLength(l Length, ref, fallback float64) float64 {
+	v, u, err := l.AsNumeric()
+	switch {
+	case err != nil || u == UnitEM || u == UnitEX:
+		return fallback
+	case u == UnitPercent:
+		return v * ref / 100
+	default:
+		return v
+	}
+}
+
+// viewportSize returns the size of the viewport that it is placed into, in
+// the user units of that viewport. The size of the root viewport is taken
+// from its viewBox, or from its absolute width and height, zero is returned
+// when it is not known.
+func (svg *Svg) viewportSize(it Item) (width, height float64) {
+	width, height = resolveLength(svg.Width, 0, 0), resolveLength(svg.Height, 0, 0)
+	if svg.ViewBox != nil {
+		width, height = svg.ViewBox.Width, svg.ViewBox.Height
+	}
+	var find func(c Item, w, h float64) bool
+	find = func(c Item, w, h float64) bool {
+		if c == it {
+			width, height = w, h
+			return true
+		}
+		if nested, ok := c.(*Svg); ok && c != svg {
+			_, _, w, h = nested.Viewport(w, h)
+			if nested.ViewBox != nil {
+				w, h = nested.ViewBox.Width, nested.ViewBox.Height
+			}
+		}
+		if cc, ok := c.(container); ok {
+			for _, ch := range cc.children() {
+				if find(ch, w, h) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	find(svg, width, height)
+	return
+}
+
+// Viewport returns the rectangle of the viewport established by a nested
+// <svg> element, in the user space of its parent. Percentages are resolved
+// against the size of the parent viewport, and the width and height default
+// to 100%.
+func (svg *Svg) Viewport(parentWidth, parentHeight float64) (x, y, width, height float64) {
+	return resolveLength(svg.X, parentWidth, 0),
+		resolveLength(svg.Y, parentHeight, 0),
+		resolveLength(svg.Width, parentWidth, parentWidth),
+		resolveLength(svg.Height, parentHeight, parentHeight)
+}
+
+// NestedViewportTransform returns the transform that a nested <svg> element
+// adds to its children: from its viewBox coordinate system into the user
+// space of its parent, see Viewport
+func (svg *Svg) NestedViewportTransform(parentWidth, parentHeight float64) *Transform {
+	x, y, w, h := svg.Viewport(parentWidth, parentHeight)
+	return ViewportTransform(svg.ViewBox, svg.PreserveAspectRatio, x, y, w, h)
+}
+
+// ClipsOverflow reports whether the content of a nested <svg> element is
+// clipped to its viewport, which is the case if the overflow property is not
+// specified, or is set to hidden, scroll or clip. The auto value is
+// equivalent to visible.
+func (svg *Svg) ClipsOverflow() bool {
+	if svg.Overflow == nil {
+		return true
+	}
+	switch *svg.Overflow {
+	case OverflowHidden, OverflowScroll, OverflowClip:
+		return true
+	default:
+		return false
+	}
+}
+
+// Symbol implements SVG <symbol> element
+type Symbol struct {
+	Node
+	Presentation
+	ViewBox             *ViewBoxValue
+	PreserveAspectRatio *PreserveAspectRatio
+	X                   Coordinate
+	Y                   Coordinate
+	Width               Length
+	Height              Length
+}
+
+func (s *Symbol) read(src sourcer) (err error) {
+	err = s.item.read(src)
+	if err != nil {
+		return
+	}
+	err = s.Presentation.read(src)
+	if err != nil {
+		return
+	}
+	err = readViewport(src, &s.ViewBox, &s.PreserveAspectRatio,
+		&s.X, &s.Y, &s.Width, &s.Height)
+	if err != nil {
+		return
+	}
+	return readChildren(src, &s.Items)
+}
+
+func (s *Symbol) write(tgt targeter) {
+	s.item.write(tgt)
+	s.Presentation.write(tgt)
+	writeViewport(tgt, s.ViewBox, s.PreserveAspectRatio, s.X, s.Y, s.Width, s.Height)
+	s.writeItems(tgt)
+}
+
+// ViewportTransform returns the transform from the symbol content coordinate
+// system into the viewport at (x, y) with the size of width by height, as
+// established by the referencing <use> element
+func (s *Symbol) ViewportTransform(x, y, width, height float64) *Transform {
+	return ViewportTransform(s.ViewBox, s.PreserveAspectRatio, x, y, width, height)
+}
+
+// readViewport reads attributes that are common to elements establishing a
+// new viewport
+func readViewport(src sourcer, vb **ViewBoxValue, par **PreserveAspectRatio, x, y *Coordinate, w, h *Length) (err error) {
+	if s, ok := src.Attr("viewBox"); ok {
+		*vb, err = ParseViewBox(s)
+		if err != nil {
+			return fmt.Errorf("invalid viewBox: %w", err)
+		}
+	}
+	if s, ok := src.Attr("preserveAspectRatio"); ok {
+		*par, err = ParsePreserveAspectRatio(s)
+		if err != nil {
+			return fmt.Errorf("invalid preserveAspectRatio: %w", err)
+		}
+	}
+	if s, ok := src.Attr("x"); ok {
+		*x = Coordinate(s)
+	}
+	if s, ok := src.Attr("y"); ok {
+		*y = Coordinate(s)
+	}
+	if s, ok := src.Attr("width"); ok {
+		*w = Length(s)
+	}
+	if s, ok := src.Attr("height"); ok {
+		*h = Length(s)
+	}
+	return
+}
+
+func writeViewport(tgt targeter, vb *ViewBoxValue, par *PreserveAspectRatio, x, y Coordinate, w, h Length) {
+	if vb != nil {
+		tgt.Attr("viewBox", vb.String())
+	}
+	if par != nil {
+		tgt.Attr("preserveAspectRatio", par.String())
+	}
+	tgt.Attr("x", string(x))
+	tgt.Attr("y", string(y))
+	tgt.Attr("width", string(w))
+	tgt.Attr("height", string(h))
+}
@@ -1,10 +1,48 @@
 package svg
 
+import "strconv"
+
 type targeter interface {
 	Attr(name, value string)
+	Property(name, value string)
 	Child(tag string, callback func(tgt targeter))
+	Text(s string)
+	Raw(markup string) // writes XML markup as is
+
+	// ExtraAttr writes an attribute that is not supported, including
+	// attributes with empty values
+	ExtraAttr(name, value string)
+
+	// StyleProperty writes a declaration within the style attribute,
+	// regardless of the property format
+	StyleProperty(d declaration)
+
+	// PropertySources sets the origins of the property values of the
+	// element that is written, these take precedence over the property
+	// format. Changed values are written inline, so that they still
+	// outrank the style sheet.
+	PropertySources(s map[string]propertySource)
 }
 
+// propertyCapture is a targeter that collects the formatted property values
+// of an element, other content is ignored
+type propertyCapture map[string]string
+
+func (pc propertyCapture) Attr(name, value string)                   {}
+func (pc propertyCapture) Property(name, value string)               { pc[name] = value }
+func (pc propertyCapture) Child(tag string, callback func(targeter)) {}
+func (pc propertyCapture) Text(s string)                             {}
+func (pc propertyCapture) Raw(markup string)                         {}
+func (pc propertyCapture) ExtraAttr(name, value string)              {}
+func (pc propertyCapture) StyleProperty(d declaration)               {}
+func (pc propertyCapture) PropertySources(map[string]propertySource) {}
+
 type writer interface {
 	write(tgt targeter)
 }
+
+// formatNumber formats a number with the minimal precision required to
+// represent it exactly
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -1,26 +1,154 @@
 package svg
 
 import (
+	"compress/gzip"
 	"io"
 
 	xg "github.com/adnsv/xmlgo"
 )
 
+// PropertyFormat selects how presentation properties are written
+type PropertyFormat int
+
+const (
+	// PropertiesAsAttributes writes each property as a presentation
+	// attribute, e.g. fill="#ffffff"
+	PropertiesAsAttributes = PropertyFormat(iota)
+
+	// PropertiesAsStyle writes all properties of an element as CSS
+	// declarations within its style attribute, e.g. style="fill:#ffffff"
+	PropertiesAsStyle
+)
+
+type writeOptions struct {
+	properties PropertyFormat
+	gzip       bool
+	gzipLevel  int
+}
+
+// WriteOption customizes the output produced by Write
+type WriteOption func(*writeOptions)
+
+// WithPropertyFormat selects how presentation properties are written, the
+// default is PropertiesAsAttributes
+func WithPropertyFormat(f PropertyFormat) WriteOption {
+	return func(o *writeOptions) {
+		o.properties = f
+	}
+}
+
+// WithGzip compresses the output with gzip, as expected for .svgz files.
+// The level is one of the compress/gzip levels, such as gzip.BestCompression
+// or gzip.DefaultCompression.
+func WithGzip(level int) WriteOption {
+	return func(o *writeOptions) {
+		o.gzip = true
+		o.gzipLevel = level
+	}
+}
+
 type xgwriter struct {
-	out *xg.Writer
+	w     io.Writer // underlying writer of out, for markup that is written as is
+	out   *xg.Writer
+	opts  writeOptions
+	style []declaration // pending declarations of the currently open tag
+
+	sources map[string]propertySource // of the currently open tag
 }
 
 func (x *xgwriter) Attr(k, v string) {
 	x.out.OptStringAttr(k, v)
 }
 
+func (x *xgwriter) Property(k, v string) {
+	if s, ok := x.sources[k]; ok {
+		// the overridden attribute is kept, it does not affect rendering
+		x.out.OptStringAttr(k, s.attr)
+		if s.sheet && s.value == v {
+			// applied by the style sheet that is written with the document
+			return
+		}
+		// a changed value must still outrank the style sheet rule, so it is
+		// written as an inline declaration rather than as an attribute
+		x.style = append(x.style, declaration{Property: k, Value: v, Important: s.important})
+		return
+	}
+	if x.opts.properties == PropertiesAsStyle {
+		x.style = append(x.style, declaration{Property: k, Value: v})
+		return
+	}
+	x.out.OptStringAttr(k, v)
+}
+
+func (x *xgwriter) ExtraAttr(k, v string) {
+	x.out.StringAttr(k, v)
+}
+
+func (x *xgwriter) StyleProperty(d declaration) {
+	x.style = append(x.style, d)
+}
+
+func (x *xgwriter) PropertySources(s map[string]propertySource) {
+	x.sources = s
+}
+
+func (x *xgwriter) flushStyle() {
+	if len(x.style) > 0 {
+		x.out.StringAttr("style", formatDeclarations(x.style))
+		x.style = nil
+	}
+}
+
 func (x *xgwriter) Child(tag string, callback func(tgt targeter)) {
+	x.flushStyle()
 	x.out.OTag(tag)
+	sources := x.sources
+	x.sources = nil
 	callback(x)
+	x.flushStyle()
+	x.sources = sources
 	x.out.CTag()
 }
 
-func Write(w io.Writer, s *Svg) {
-	xgw := xgwriter{out: xg.NewWriter(w)}
+func (x *xgwriter) Text(s string) {
+	if len(s) > 0 {
+		x.flushStyle()
+		x.out.String(s)
+	}
+}
+
+func (x *xgwriter) Raw(markup string) {
+	if len(markup) > 0 {
+		x.flushStyle()
+		x.out.BeginContent()
+		io.WriteString(x.w, markup)
+	}
+}
+
+// Write writes the document to w. An error is only returned for the output
+// that is compressed with WithGzip.
+func Write(w io.Writer, s *Svg, opts ...WriteOption) error {
+	o := writeOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.gzip {
+		writeDocument(w, s, o)
+		return nil
+	}
+	zw, err := gzip.NewWriterLevel(w, o.gzipLevel)
+	if err != nil {
+		return err
+	}
+	writeDocument(zw, s, o)
+	return zw.Close()
+}
+
+func writeDocument(w io.Writer, s *Svg, o writeOptions) {
+	if s.prolog != "" {
+		io.WriteString(w, s.prolog+"\n")
+	}
+	xgw := xgwriter{w: w, out: xg.NewWriter(w), opts: o}
 	xgw.Child("svg", func(tgt targeter) { s.write(tgt) })
+	io.WriteString(w, s.epilog)
 }
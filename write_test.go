@@ -0,0 +1,166 @@
+package svg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func roundTrip(t *testing.T, data string, opts ...WriteOption) (*Svg, *Svg, string) {
+	t.Helper()
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	Write(&buf, doc, opts...)
+	doc2, err := Parse(buf.String())
+	if err != nil {
+		t.Fatalf("%s\nin written document:\n%s", err, buf.String())
+	}
+	return doc, doc2, buf.String()
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	data := `<svg viewBox="0 0 24 24" width="24" height="24">
+		<g id="g1" opacity="0.5" transform="translate(1,2)">
+			<rect x="1" y="2" width="3" height="4" rx="1" fill="#123456" fill-rule="evenodd" fill-opacity="0.25"/>
+			<circle cx="12" cy="12" r="10" fill="none" stroke="#abc" stroke-width="2"
+				stroke-opacity="0.75" stroke-linecap="square" stroke-linejoin="round"
+				stroke-miterlimit="4" stroke-dasharray="1 2" stroke-dashoffset="3"/>
+			<polyline points="1,1 2,2 3,1" stroke-dasharray="none" opacity="0.9"/>
+			<path d="M1,1L2,2" transform="scale(2)"/>
+		</g>
+	</svg>`
+
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+	if _, ok := doc2.Items[0].(*Group).Items[2].(*Polyline); !ok {
+		t.Errorf("polyline is not preserved")
+	}
+}
+
+func TestWritePropertyFormat(t *testing.T) {
+	doc, err := Parse(`<svg><rect width="1" fill="#fff" stroke-width="2"><title/></rect></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	if want := `<rect fill="#ffffff" stroke-width="2" width="1"`; !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	Write(&buf, doc, WithPropertyFormat(PropertiesAsStyle))
+	if want := `<rect width="1" style="fill:#ffffff;stroke-width:2"`; !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+
+	doc2, err := Parse(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(doc, clearPropertySources(doc2)) {
+		t.Errorf("style round trip mismatch:\n%s", buf.String())
+	}
+}
+
+// clearPropertySources forgets the origins of property values. Writing with
+// PropertiesAsStyle moves the values of presentation attributes into the
+// style attribute, the documents parsed back differ in the origins only.
+func clearPropertySources(doc *Svg) *Svg {
+	Walk(doc, func(it Item) bool {
+		if b, ok := it.(interface{ base() *item }); ok {
+			b.base().sources = nil
+		}
+		return true
+	})
+	return doc
+}
+
+func TestWriteStyleOrigins(t *testing.T) {
+	data := `<svg><style>rect{fill:red} .b{stroke:blue}</style>` +
+		`<rect id="r1" style="fill:blue"/>` +
+		`<rect id="r2" class="b" stroke="green"/>` +
+		`<rect id="r3" fill="lime" style="fill:yellow !important"/>` +
+		`</svg>`
+	doc, doc2, out := roundTrip(t, data)
+	if !reflect.DeepEqual(doc, doc2) {
+		t.Errorf("round trip mismatch, written document:\n%s", out)
+	}
+	for _, want := range []string{
+		`<rect id="r1" style="fill:#0000ff" />`,
+		`<rect id="r2" class="b" stroke="green" />`,
+		`<rect id="r3" fill="lime" style="fill:#ffff00 !important" />`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+	if f := doc2.ByID("r1").(*Rect).Fill; f == nil || f.Color != (RGB{0, 0, 0xff}) {
+		t.Errorf("r1 fill after round trip = %v", f)
+	}
+
+	// a changed value of the style sheet is written inline, so that it
+	// outranks the rule
+	doc.ByID("r2").(*Rect).Stroke = &Paint{Kind: PaintKindRGB, Color: RGB{0xff, 0, 0}}
+	buf := bytes.Buffer{}
+	Write(&buf, doc)
+	if want := `<rect id="r2" class="b" stroke="green" style="stroke:#ff0000" />`; !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %s in:\n%s", want, buf.String())
+	}
+
+	doc, err := Parse(`<svg><style>.st0{fill:red} .st1{fill:red !important}</style>` +
+		`<rect id="a" class="st0"/><rect id="b" class="st1"/></svg>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blue := &Paint{Kind: PaintKindRGB, Color: RGB{0, 0, 0xff}}
+	doc.ByID("a").(*Rect).Fill = blue
+	doc.ByID("b").(*Rect).Fill = blue
+	buf.Reset()
+	Write(&buf, doc)
+	for _, want := range []string{
+		`<rect id="a" class="st0" style="fill:#0000ff" />`,
+		`<rect id="b" class="st1" style="fill:#0000ff !important" />`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %s in:\n%s", want, buf.String())
+		}
+	}
+	doc2, err = Parse(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if f := doc2.ByID(id).(*Rect).Fill; f == nil || f.Color != blue.Color {
+			t.Errorf("%s: edited fill is lost: %v", id, f)
+		}
+	}
+}
+
+func TestWriteRoundTripTestData(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range files {
+		data, err := os.ReadFile(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(filepath.Base(fn), func(t *testing.T) {
+			doc, doc2, out := roundTrip(t, string(data))
+			if !reflect.DeepEqual(doc, doc2) {
+				t.Errorf("round trip mismatch, written document:\n%s", out)
+			}
+		})
+	}
+}